@@ -0,0 +1,156 @@
+package water
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+type accessLog struct {
+	logger        *slog.Logger
+	sampleRate    float64
+	slow          time.Duration
+	redactQuery   map[string]bool
+	headers       []string
+	redactHeaders map[string]bool
+}
+
+type AccessLogOption func(a *accessLog)
+
+// AccessLogLogger 指定输出的日志，默认为框架日志
+func AccessLogLogger(l *slog.Logger) AccessLogOption {
+	return func(a *accessLog) { a.logger = l }
+}
+
+// AccessLogSample 成功请求的采样率，取值 0~1，默认全部记录；错误和慢请求总是记录
+func AccessLogSample(rate float64) AccessLogOption {
+	return func(a *accessLog) { a.sampleRate = rate }
+}
+
+// AccessLogSlow 耗时超过 threshold 的请求总是记录，并以 WARN 级别输出
+func AccessLogSlow(threshold time.Duration) AccessLogOption {
+	return func(a *accessLog) { a.slow = threshold }
+}
+
+// AccessLogRedactQuery 需要脱敏的查询参数，默认包含 api_key、access_token 和 token
+func AccessLogRedactQuery(names ...string) AccessLogOption {
+	return func(a *accessLog) {
+		for _, name := range names {
+			a.redactQuery[name] = true
+		}
+	}
+}
+
+// AccessLogHeaders 需要记录的请求头
+func AccessLogHeaders(names ...string) AccessLogOption {
+	return func(a *accessLog) { a.headers = append(a.headers, names...) }
+}
+
+// AccessLogRedactHeaders 需要脱敏的请求头，默认包含 Authorization、Cookie 和 X-API-Key
+func AccessLogRedactHeaders(names ...string) AccessLogOption {
+	return func(a *accessLog) {
+		for _, name := range names {
+			a.redactHeaders[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// AccessLog 每个请求输出一条访问日志，包含方法、路由、路径、状态码、字节数、耗时、客户端 IP、用户和请求 ID
+func AccessLog(options ...AccessLogOption) Middleware {
+	a := &accessLog{
+		sampleRate: 1,
+		redactQuery: map[string]bool{
+			"api_key":      true,
+			"access_token": true,
+			"token":        true,
+		},
+		redactHeaders: map[string]bool{
+			"Authorization": true,
+			"Cookie":        true,
+			"X-Api-Key":     true,
+		},
+	}
+	for _, option := range options {
+		option(a)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			begin := time.Now()
+			next(c)
+			latency := time.Since(begin)
+
+			status := c.ResponseStatus()
+			slow := a.slow > 0 && latency >= a.slow
+			failed := status >= http.StatusBadRequest
+			if !failed && !slow && a.sampleRate < 1 && rand.Float64() >= a.sampleRate {
+				return
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", c.Request.Method),
+				slog.String("route", c.Route()),
+				slog.String("path", a.path(c.Request.URL)),
+				slog.Int("status", status),
+				slog.Int("bytes", c.ResponseSize()),
+				slog.Duration("latency", latency),
+				slog.String("client_ip", c.ClientIP()),
+			}
+			if user := c.Subject(); user != "" {
+				attrs = append(attrs, slog.String("user", user))
+			}
+			if id := c.RequestID(); id != "" {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+			if len(a.headers) > 0 {
+				headers := make([]any, 0, len(a.headers))
+				for _, name := range a.headers {
+					if value := c.GetHeader(name); value != "" {
+						if a.redactHeaders[http.CanonicalHeaderKey(name)] {
+							value = redacted
+						}
+						headers = append(headers, slog.String(name, value))
+					}
+				}
+				attrs = append(attrs, slog.Group("headers", headers...))
+			}
+
+			level := slog.LevelInfo
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case failed || slow:
+				level = slog.LevelWarn
+			}
+
+			l := a.logger
+			if l == nil {
+				l = log
+			}
+			l.LogAttrs(c.Request.Context(), level, "access", attrs...)
+		}
+	}
+}
+
+func (a *accessLog) path(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+	if len(a.redactQuery) == 0 {
+		return u.Path + "?" + u.RawQuery
+	}
+
+	query := u.Query()
+	for name := range query {
+		if a.redactQuery[name] {
+			query[name] = []string{redacted}
+		}
+	}
+
+	return u.Path + "?" + strings.ReplaceAll(query.Encode(), url.QueryEscape(redacted), redacted)
+}
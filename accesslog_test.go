@@ -0,0 +1,61 @@
+package water
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAccessLogRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	w := New()
+	h := AccessLog(AccessLogLogger(l), AccessLogHeaders("Authorization", "User-Agent"))(func(c *Context) {
+		c.Status(204)
+	})
+
+	req := httptest.NewRequest("GET", "/users/1?api_key=secret&page=2", nil)
+	req.Pattern = "GET /users/{id}"
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("User-Agent", "test")
+	serve(w, h, req)
+
+	if strings.Contains(buf.String(), "secret") {
+		t.Fatalf("access log leaks a secret: %s", buf.String())
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["route"] != "/users/{id}" || record["status"] != float64(204) {
+		t.Fatalf("record = %v", record)
+	}
+	if path := record["path"].(string); !strings.Contains(path, "api_key=[REDACTED]") || !strings.Contains(path, "page=2") {
+		t.Fatalf("path = %q", path)
+	}
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	w := New()
+	status := 200
+	h := AccessLog(AccessLogLogger(l), AccessLogSample(0))(func(c *Context) { c.Status(status) })
+
+	serve(w, h, httptest.NewRequest("GET", "/", nil))
+	if buf.Len() != 0 {
+		t.Fatalf("sampled-out success was logged: %s", buf.String())
+	}
+
+	status = 500
+	serve(w, h, httptest.NewRequest("GET", "/", nil))
+	if !strings.Contains(buf.String(), `"level":"ERROR"`) {
+		t.Fatalf("error was not logged: %s", buf.String())
+	}
+}
@@ -0,0 +1,129 @@
+package water
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-water/water/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/time/rate"
+)
+
+type apiKeyAuth struct {
+	store    auth.KeyStore
+	header   string
+	query    string
+	limiters sync.Map // map[string]*rate.Limiter (key ID or hash -> Limiter)
+	hashes   sync.Map // map[string]string (hash -> limiter key)
+}
+
+type APIKeyOption func(a *apiKeyAuth)
+
+// APIKeyHeader 读取密钥的请求头，默认 X-API-Key，为空时不读取
+func APIKeyHeader(name string) APIKeyOption {
+	return func(a *apiKeyAuth) { a.header = name }
+}
+
+// APIKeyQuery 从查询参数读取密钥，默认不读取；查询参数会被写入访问日志和代理日志，
+// 只在客户端无法设置请求头时使用，并配合 AccessLogRedactQuery 脱敏
+func APIKeyQuery(name string) APIKeyOption {
+	return func(a *apiKeyAuth) { a.query = name }
+}
+
+// APIKeyAuth 通过 KeyStore 校验 API Key，成功后把权限范围以声明的形式保存到 Context，
+// 与 JWTAuth 一样可以被 RequireScopes 和用户限流读取
+func APIKeyAuth(store auth.KeyStore, options ...APIKeyOption) Middleware {
+	a := &apiKeyAuth{
+		store:  store,
+		header: "X-API-Key",
+	}
+	for _, option := range options {
+		option(a)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			rawKey := ""
+			if a.header != "" {
+				rawKey = c.GetHeader(a.header)
+			}
+			if rawKey == "" && a.query != "" {
+				rawKey = c.Query(a.query)
+			}
+			if rawKey == "" {
+				_ = c.JSON(http.StatusUnauthorized, H{"err": ErrUnauthenticated.Error()})
+				return
+			}
+
+			hash := auth.HashAPIKey(rawKey)
+			key, err := a.store.Lookup(c.Request.Context(), hash)
+			if err == nil && !key.ExpiresAt.IsZero() && time.Now().After(key.ExpiresAt) {
+				err = auth.ErrAPIKeyExpired
+			}
+			if err != nil {
+				if errors.Is(err, auth.ErrAPIKeyInvalid) || errors.Is(err, auth.ErrAPIKeyExpired) {
+					a.forget(hash)
+				} else {
+					log.Error("api key lookup failed", "err", err.Error())
+				}
+				_ = c.JSON(http.StatusUnauthorized, H{"err": ErrUnauthenticated.Error()})
+				return
+			}
+
+			if key.RateInterval > 0 && !a.limiter(key, hash).Allow() {
+				_ = c.JSON(http.StatusTooManyRequests, H{"err": "rate limit exceeded"})
+				return
+			}
+
+			subject := key.Subject
+			if subject == "" {
+				subject = "apikey:" + key.ID
+			}
+
+			c.Set(APIKeyKey, key)
+			c.SetClaims(&auth.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{ID: key.ID, Subject: subject},
+				Scopes:           key.Scopes,
+			})
+			next(c)
+		}
+	}
+}
+
+// limiter 返回密钥的限流器，按 ID 区分，ID 为空时按密钥哈希区分，KeyStore 中的限流配置变化时同步更新
+func (a *apiKeyAuth) limiter(key *auth.APIKey, hash string) *rate.Limiter {
+	limit := rate.Every(key.RateInterval)
+	burst := key.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+
+	id := key.ID
+	if id == "" {
+		id = hash
+	}
+	a.hashes.Store(hash, id)
+
+	if v, ok := a.limiters.Load(id); ok {
+		limiter := v.(*rate.Limiter)
+		if limiter.Limit() != limit {
+			limiter.SetLimit(limit)
+		}
+		if limiter.Burst() != burst {
+			limiter.SetBurst(burst)
+		}
+		return limiter
+	}
+
+	v, _ := a.limiters.LoadOrStore(id, rate.NewLimiter(limit, burst))
+	return v.(*rate.Limiter)
+}
+
+// forget 密钥被吊销或过期后删除对应的限流器，避免 map 无限增长
+func (a *apiKeyAuth) forget(hash string) {
+	if id, ok := a.hashes.LoadAndDelete(hash); ok {
+		a.limiters.Delete(id)
+	}
+}
@@ -0,0 +1,174 @@
+package water
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-water/water/auth"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	store := auth.NewMemoryKeyStore()
+	store.Add("valid", auth.APIKey{ID: "k1", Scopes: []string{"read"}})
+	store.Add("expired", auth.APIKey{ID: "k2", ExpiresAt: time.Now().Add(-time.Minute)})
+
+	w := New()
+	var subject string
+	var scopes []string
+	h := APIKeyAuth(store)(func(c *Context) {
+		subject = c.Subject()
+		scopes = scopesFromContext(c)
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		target string
+		want   int
+	}{
+		{"valid header", "valid", "/", http.StatusNoContent},
+		{"missing", "", "/", http.StatusUnauthorized},
+		{"unknown", "other", "/", http.StatusUnauthorized},
+		{"expired", "expired", "/", http.StatusUnauthorized},
+		{"query ignored by default", "", "/?api_key=valid", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set("X-API-Key", tt.header)
+			}
+			if rec := serve(w, h, req); rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	serve(w, h, func() *http.Request {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", "valid")
+		return req
+	}())
+	if subject != "apikey:k1" || len(scopes) != 1 || scopes[0] != "read" {
+		t.Fatalf("subject = %q, scopes = %v", subject, scopes)
+	}
+}
+
+func TestAPIKeyQueryOptIn(t *testing.T) {
+	store := auth.NewMemoryKeyStore()
+	store.Add("valid", auth.APIKey{ID: "k1"})
+
+	h := APIKeyAuth(store, APIKeyQuery("api_key"))(func(c *Context) { c.Status(http.StatusNoContent) })
+	if rec := serve(New(), h, httptest.NewRequest("GET", "/?api_key=valid", nil)); rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", rec.Code)
+	}
+}
+
+func TestAPIKeyRateLimit(t *testing.T) {
+	store := auth.NewMemoryKeyStore()
+	hash := store.Add("valid", auth.APIKey{ID: "k1", RateInterval: time.Hour, Burst: 1})
+
+	w := New()
+	h := APIKeyAuth(store)(func(c *Context) { c.Status(http.StatusNoContent) })
+	do := func() int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", "valid")
+		return serve(w, h, req).Code
+	}
+
+	if code := do(); code != http.StatusNoContent {
+		t.Fatalf("first request status = %d", code)
+	}
+	if code := do(); code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d, want 429", code)
+	}
+
+	// 修改密钥的限流配置后立即生效
+	store.AddHash(hash, auth.APIKey{ID: "k1", RateInterval: time.Nanosecond, Burst: 10})
+	for i := 0; i < 5; i++ {
+		if code := do(); code != http.StatusNoContent {
+			t.Fatalf("request %d after raising the limit: status = %d", i, code)
+		}
+	}
+}
+
+func TestAPIKeyRateLimitWithoutID(t *testing.T) {
+	store := auth.NewMemoryKeyStore()
+	store.Add("first", auth.APIKey{RateInterval: time.Hour, Burst: 1})
+	store.Add("second", auth.APIKey{RateInterval: time.Hour, Burst: 1})
+
+	w := New()
+	h := APIKeyAuth(store)(func(c *Context) { c.Status(http.StatusNoContent) })
+	do := func(key string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", key)
+		return serve(w, h, req).Code
+	}
+
+	// 没有 ID 的密钥按哈希区分，不共享限流器
+	if code := do("first"); code != http.StatusNoContent {
+		t.Fatalf("first key status = %d", code)
+	}
+	if code := do("second"); code != http.StatusNoContent {
+		t.Fatalf("second key status = %d, want its own limiter", code)
+	}
+	if code := do("first"); code != http.StatusTooManyRequests {
+		t.Fatalf("first key again status = %d, want 429", code)
+	}
+}
+
+func TestAPIKeyRevokeDropsLimiter(t *testing.T) {
+	store := auth.NewMemoryKeyStore()
+	hash := store.Add("valid", auth.APIKey{ID: "k1", RateInterval: time.Hour, Burst: 1})
+
+	w := New()
+	h := APIKeyAuth(store)(func(c *Context) { c.Status(http.StatusNoContent) })
+	do := func() int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", "valid")
+		return serve(w, h, req).Code
+	}
+
+	if code := do(); code != http.StatusNoContent {
+		t.Fatalf("first request status = %d", code)
+	}
+
+	tests := []struct {
+		name   string
+		revoke func()
+	}{
+		{"revoked", func() { store.Remove(hash) }},
+		{"expired", func() {
+			store.AddHash(hash, auth.APIKey{ID: "k1", RateInterval: time.Hour, Burst: 1, ExpiresAt: time.Now().Add(-time.Minute)})
+		}},
+	}
+	for _, tt := range tests {
+		tt.revoke()
+		if code := do(); code != http.StatusUnauthorized {
+			t.Fatalf("%s key status = %d, want 401", tt.name, code)
+		}
+
+		// 重新启用后使用新的限流器，说明旧的已被删除
+		store.AddHash(hash, auth.APIKey{ID: "k1", RateInterval: time.Hour, Burst: 1})
+		if code := do(); code != http.StatusNoContent {
+			t.Fatalf("re-enabled %s key status = %d, want a fresh limiter", tt.name, code)
+		}
+	}
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	a, err := auth.GenerateAPIKey("wt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := auth.GenerateAPIKey("wt")
+	if a == b || a[:3] != "wt_" {
+		t.Fatalf("GenerateAPIKey() = %q, %q", a, b)
+	}
+	if auth.HashAPIKey(a) == a || auth.HashAPIKey(a) != auth.HashAPIKey(a) {
+		t.Fatal("HashAPIKey is not a stable hash")
+	}
+}
@@ -3,11 +3,11 @@ package water
 import (
 	"net/http"
 	"os"
-	"strings"
+	"sync"
 	"time"
 
+	"github.com/go-water/water/auth"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/golang-jwt/jwt/v5/request"
 )
 
 func SetAuthToken(uniqueUser, issuer, privateKeyPath string, expire time.Duration) (tokenString string, err error) {
@@ -16,66 +16,100 @@ func SetAuthToken(uniqueUser, issuer, privateKeyPath string, expire time.Duratio
 		return "", err
 	}
 
-	signingKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKey)
+	signingKey, err := auth.ParsePrivateKeyPEM(privateKey)
 	if err != nil {
 		return "", err
 	}
 
+	signer, err := auth.NewJWTSigner(auth.RS512, signingKey)
+	if err != nil {
+		return "", err
+	}
+
+	// 旧版只写入 jti，ParseFromRequest 仍从 jti 读取用户，sub 供 JWTAuth 和 Context.Subject 使用
 	claims := jwt.RegisteredClaims{
 		ID:        uniqueUser,
+		Subject:   uniqueUser,
 		Issuer:    issuer,
 		ExpiresAt: jwt.NewNumericDate(time.Now().Add(expire)),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS512, claims)
-	tokenString, err = token.SignedString(signingKey)
+	return signer.Sign(claims)
+}
+
+// ParseFromRequest 兼容 http,ws，公钥文件只加载一次，文件变化时自动重新加载
+func ParseFromRequest(req *http.Request, publicKeyPath string) (uniqueUser, issuer, signature string, err error) {
+	verifier, err := fileVerifier(publicKeyPath)
 	if err != nil {
-		return "", err
+		return "", "", "", err
+	}
+
+	return ParseFromRequestWithVerifier(req, verifier)
+}
+
+// ParseFromRequestWithVerifier 使用指定的 verifier 校验请求中的 token
+func ParseFromRequestWithVerifier(req *http.Request, verifier *auth.JWTVerifier) (uniqueUser, issuer, signature string, err error) {
+	tokenString, err := legacyExtractor.ExtractToken(req)
+	if err != nil {
+		return "", "", "", jwt.ErrTokenSignatureInvalid
 	}
 
-	parts := strings.Split(tokenString, ".")
-	if len(parts) != 3 {
-		return "", jwt.ErrTokenSignatureInvalid
+	token, err := verifier.Verify(tokenString, &jwt.RegisteredClaims{})
+	if err != nil {
+		return "", "", "", err
 	}
 
-	return tokenString, nil
+	return parseToken(token)
 }
 
-// ParseFromRequest 兼容 http,ws
-func ParseFromRequest(req *http.Request, publicKeyPath string) (uniqueUser, issuer, signature string, err error) {
-	token, err := request.ParseFromRequest(req, request.AuthorizationHeaderExtractor, func(t *jwt.Token) (any, error) {
-		publicKey, innErr := os.ReadFile(publicKeyPath)
-		if innErr != nil {
-			return "", innErr
-		}
+// legacyExtractor 保持 ParseFromRequest 原有行为，只有一项的子协议也作为 token
+var legacyExtractor = auth.MultiExtractor{auth.DefaultExtractor, auth.SubprotocolExtractor{}}
+
+var (
+	keyReloadInterval = time.Minute
+	fileVerifiers     sync.Map // map[string]*fileKey (public key path -> verifier)
+)
 
-		return jwt.ParseRSAPublicKeyFromPEM(publicKey)
-	}, request.WithClaims(&jwt.RegisteredClaims{}))
+// fileKey 缓存的公钥文件及其校验器，keys 的后台协程由 CloseKeyFiles 停止
+type fileKey struct {
+	verifier *auth.JWTVerifier
+	keys     *auth.FileKeySet
+}
 
-	if token != nil && token.Valid {
-		return parseToken(token)
+func fileVerifier(publicKeyPath string) (*auth.JWTVerifier, error) {
+	if v, ok := fileVerifiers.Load(publicKeyPath); ok {
+		return v.(*fileKey).verifier, nil
 	}
 
-	// 兼容 ws
-	if wsp := req.Header.Get("Sec-Websocket-Protocol"); len(wsp) > 0 {
-		token, err = jwt.ParseWithClaims(wsp, &jwt.RegisteredClaims{}, func(t *jwt.Token) (any, error) {
-			publicKey, innErr := os.ReadFile(publicKeyPath)
-			if innErr != nil {
-				return "", innErr
-			}
-
-			return jwt.ParseRSAPublicKeyFromPEM(publicKey)
-		})
-		if err != nil {
-			return "", "", "", err
-		}
+	keys, err := auth.LoadKeyFiles(map[string]string{"": publicKeyPath})
+	if err != nil {
+		return nil, err
+	}
 
-		if token.Valid {
-			return parseToken(token)
+	// 单个公钥文件兼容旧版，无论 token 头部是否带 kid 都使用该公钥
+	resolver := auth.KeyResolverFunc(func(*jwt.Token) (any, error) {
+		if key, ok := keys.Key(""); ok {
+			return key, nil
 		}
+		return nil, auth.ErrKeyNotFound
+	})
+	verifier := auth.NewJWTVerifier(resolver, auth.VerifierMethods(auth.RS256, auth.RS384, auth.RS512))
+	v, loaded := fileVerifiers.LoadOrStore(publicKeyPath, &fileKey{verifier: verifier, keys: keys})
+	if !loaded {
+		keys.Watch(keyReloadInterval)
 	}
 
-	return "", "", "", jwt.ErrTokenSignatureInvalid
+	return v.(*fileKey).verifier, nil
+}
+
+// CloseKeyFiles 停止 ParseFromRequest 监听公钥文件的协程并清空缓存，Water.Shutdown 会自动调用，
+// 之后再次调用 ParseFromRequest 会重新加载公钥
+func CloseKeyFiles() {
+	fileVerifiers.Range(func(path, v any) bool {
+		fileVerifiers.Delete(path)
+		v.(*fileKey).keys.Close()
+		return true
+	})
 }
 
 func parseToken(token *jwt.Token) (uniqueUser, issuer, signature string, err error) {
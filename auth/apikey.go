@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrAPIKeyInvalid = errors.New("auth: invalid api key")
+	ErrAPIKeyExpired = errors.New("auth: api key expired")
+)
+
+// APIKey 机器客户端的密钥信息，RateInterval 为 0 时不限流
+type APIKey struct {
+	ID           string
+	Name         string
+	Subject      string
+	Scopes       []string
+	RateInterval time.Duration
+	Burst        int
+	ExpiresAt    time.Time
+}
+
+// KeyStore 按密钥哈希查找 API Key，实现方只保存 HashAPIKey 的结果
+type KeyStore interface {
+	Lookup(ctx context.Context, hash string) (*APIKey, error)
+}
+
+// HashAPIKey 计算保存和查找用的密钥哈希
+func HashAPIKey(key string) string {
+	return hashToken(key)
+}
+
+// GenerateAPIKey 生成随机密钥，明文只在创建时返回一次
+func GenerateAPIKey(prefix string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	key := base64.RawURLEncoding.EncodeToString(b)
+	if prefix != "" {
+		key = prefix + "_" + key
+	}
+
+	return key, nil
+}
+
+type MemoryKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]APIKey
+}
+
+func NewMemoryKeyStore() *MemoryKeyStore {
+	return &MemoryKeyStore{keys: make(map[string]APIKey)}
+}
+
+// Add 保存明文密钥的哈希，返回该哈希
+func (s *MemoryKeyStore) Add(rawKey string, key APIKey) string {
+	hash := HashAPIKey(rawKey)
+	s.AddHash(hash, key)
+	return hash
+}
+
+func (s *MemoryKeyStore) AddHash(hash string, key APIKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[hash] = key
+}
+
+func (s *MemoryKeyStore) Remove(hash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, hash)
+}
+
+func (s *MemoryKeyStore) Lookup(_ context.Context, hash string) (*APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[hash]
+	if !ok {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims 默认的自定义声明，携带角色、权限范围和租户
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles    []string `json:"roles,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+	TenantID string   `json:"tid,omitempty"`
+}
+
+func (c *Claims) GetRoles() []string {
+	return c.Roles
+}
+
+func (c *Claims) GetScopes() []string {
+	return c.Scopes
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
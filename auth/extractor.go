@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5/request"
+)
+
+// ErrNoToken 请求中没有 token
+var ErrNoToken = request.ErrNoTokenInRequest
+
+// Extractor 从请求中提取 token，与 request.Extractor 兼容
+type Extractor interface {
+	ExtractToken(r *http.Request) (string, error)
+}
+
+// SubprotocolSelector 由从 WebSocket 子协议中提取 token 的 Extractor 实现，
+// 返回需要在握手响应中回显的子协议
+type SubprotocolSelector interface {
+	SelectedSubprotocol(r *http.Request) string
+}
+
+// HeaderExtractor 从请求头提取 token，Scheme 非空时要求值以 "Scheme " 开头
+type HeaderExtractor struct {
+	Name   string
+	Scheme string
+}
+
+func (e HeaderExtractor) ExtractToken(r *http.Request) (string, error) {
+	value := strings.TrimSpace(r.Header.Get(e.Name))
+	if e.Scheme != "" {
+		if len(value) <= len(e.Scheme) || !strings.EqualFold(value[:len(e.Scheme)], e.Scheme) || value[len(e.Scheme)] != ' ' {
+			return "", ErrNoToken
+		}
+		value = strings.TrimSpace(value[len(e.Scheme)+1:])
+	}
+
+	if value == "" {
+		return "", ErrNoToken
+	}
+	return value, nil
+}
+
+// BearerExtractor 从 Authorization: Bearer <token> 提取
+var BearerExtractor = HeaderExtractor{Name: "Authorization", Scheme: "Bearer"}
+
+// CookieExtractor 从指定名称的 cookie 提取
+type CookieExtractor string
+
+func (e CookieExtractor) ExtractToken(r *http.Request) (string, error) {
+	cookie, err := r.Cookie(string(e))
+	if err != nil || cookie.Value == "" {
+		return "", ErrNoToken
+	}
+
+	return cookie.Value, nil
+}
+
+// QueryExtractor 从指定名称的查询参数提取
+type QueryExtractor string
+
+func (e QueryExtractor) ExtractToken(r *http.Request) (string, error) {
+	if value := r.URL.Query().Get(string(e)); value != "" {
+		return value, nil
+	}
+
+	return "", ErrNoToken
+}
+
+// SubprotocolExtractor 从 Sec-WebSocket-Protocol 列表中提取 token
+// 浏览器发送 "bearer, <token>" 时，Protocol 为 "bearer"，取其后一项作为 token，并原样回显客户端发送的 "bearer"
+// Protocol 为空时兼容旧版，只有一项时把整个头部作为 token
+type SubprotocolExtractor struct {
+	Protocol string
+}
+
+func (e SubprotocolExtractor) ExtractToken(r *http.Request) (string, error) {
+	protocols := subprotocols(r)
+	if e.Protocol == "" {
+		if len(protocols) == 1 {
+			return protocols[0], nil
+		}
+		return "", ErrNoToken
+	}
+
+	for i, protocol := range protocols {
+		if strings.EqualFold(protocol, e.Protocol) && i+1 < len(protocols) {
+			return protocols[i+1], nil
+		}
+	}
+
+	return "", ErrNoToken
+}
+
+// SelectedSubprotocol 返回客户端发送的与 Protocol 匹配的子协议，浏览器要求回显的值与发送的完全一致
+func (e SubprotocolExtractor) SelectedSubprotocol(r *http.Request) string {
+	if e.Protocol == "" {
+		return ""
+	}
+
+	for _, protocol := range subprotocols(r) {
+		if strings.EqualFold(protocol, e.Protocol) {
+			return protocol
+		}
+	}
+
+	return ""
+}
+
+func subprotocols(r *http.Request) []string {
+	var protocols []string
+	for _, value := range r.Header.Values("Sec-Websocket-Protocol") {
+		for _, protocol := range strings.Split(value, ",") {
+			if protocol = strings.TrimSpace(protocol); protocol != "" {
+				protocols = append(protocols, protocol)
+			}
+		}
+	}
+
+	return protocols
+}
+
+// MultiExtractor 按顺序尝试，返回第一个提取到的 token
+type MultiExtractor []Extractor
+
+func (e MultiExtractor) ExtractToken(r *http.Request) (string, error) {
+	token, _, err := e.Extract(r)
+	return token, err
+}
+
+// Extract 同 ExtractToken，同时返回命中的 Extractor
+func (e MultiExtractor) Extract(r *http.Request) (string, Extractor, error) {
+	for _, extractor := range e {
+		if token, err := extractor.ExtractToken(r); err == nil && token != "" {
+			return token, extractor, nil
+		}
+	}
+
+	return "", nil, ErrNoToken
+}
+
+// DefaultExtractor 依次尝试 Authorization 头部和 "bearer, <token>" 形式的 WebSocket 子协议
+// 只有一项的子协议（如 graphql-ws）不会被当作 token，需要兼容旧版时追加 SubprotocolExtractor{}
+var DefaultExtractor = MultiExtractor{
+	BearerExtractor,
+	SubprotocolExtractor{Protocol: "bearer"},
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBearerExtractor(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"Bearer abc", "abc"},
+		{"bearer  abc ", "abc"},
+		{"Bearerabc", ""},
+		{"Basic abc", ""},
+		{"Bearer ", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Authorization", tt.header)
+		got, err := BearerExtractor.ExtractToken(r)
+		if got != tt.want || (tt.want == "" && !errors.Is(err, ErrNoToken)) {
+			t.Errorf("ExtractToken(%q) = %q, %v", tt.header, got, err)
+		}
+	}
+}
+
+func TestSubprotocolExtractorEchoesClientValue(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Sec-WebSocket-Protocol", "chat, Bearer, tok123")
+
+	e := SubprotocolExtractor{Protocol: "bearer"}
+	token, err := e.ExtractToken(r)
+	if err != nil || token != "tok123" {
+		t.Fatalf("ExtractToken() = %q, %v", token, err)
+	}
+	if got := e.SelectedSubprotocol(r); got != "Bearer" {
+		t.Fatalf("SelectedSubprotocol() = %q, want the client's %q", got, "Bearer")
+	}
+}
+
+func TestDefaultExtractorOrder(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?token=query", nil)
+	r.Header.Set("Authorization", "Bearer header")
+	r.Header.Set("Sec-WebSocket-Protocol", "bearer, proto")
+
+	token, extractor, err := DefaultExtractor.Extract(r)
+	if err != nil || token != "header" || extractor != BearerExtractor {
+		t.Fatalf("Extract() = %q, %v, %v", token, extractor, err)
+	}
+
+	r.Header.Del("Authorization")
+	token, extractor, _ = DefaultExtractor.Extract(r)
+	if token != "proto" {
+		t.Fatalf("Extract() without header = %q", token)
+	}
+	if _, ok := extractor.(SubprotocolSelector); !ok {
+		t.Fatal("subprotocol extractor does not select a subprotocol")
+	}
+
+	// 只有一项的子协议是普通协议名，不是 token
+	r.Header.Set("Sec-WebSocket-Protocol", "graphql-ws")
+	if _, _, err = DefaultExtractor.Extract(r); !errors.Is(err, ErrNoToken) {
+		t.Fatalf("Extract() from single subprotocol = %v, want ErrNoToken", err)
+	}
+
+	// 查询参数默认不读取
+	r.Header.Del("Sec-WebSocket-Protocol")
+	if _, _, err = DefaultExtractor.Extract(r); !errors.Is(err, ErrNoToken) {
+		t.Fatalf("Extract() from query = %v, want ErrNoToken", err)
+	}
+}
+
+func TestLegacySubprotocolExtractor(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Sec-WebSocket-Protocol", "legacy-token")
+
+	token, err := SubprotocolExtractor{}.ExtractToken(r)
+	if err != nil || token != "legacy-token" {
+		t.Fatalf("ExtractToken() = %q, %v", token, err)
+	}
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"sync"
+	"testing"
+)
+
+var (
+	rsaOnce sync.Once
+	rsaKeys []*rsa.PrivateKey
+)
+
+// testRSAKey 返回测试用的 RSA 私钥，生成较慢，在同一进程中复用
+func testRSAKey(t *testing.T, i int) *rsa.PrivateKey {
+	t.Helper()
+	rsaOnce.Do(func() {
+		for range 2 {
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			if err != nil {
+				panic(err)
+			}
+			rsaKeys = append(rsaKeys, key)
+		}
+	})
+
+	return rsaKeys[i]
+}
+
+func testSigner(t *testing.T, alg string, key any, options ...SignerOption) *JWTSigner {
+	t.Helper()
+	signer, err := NewJWTSigner(alg, key, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func testToken(t *testing.T, signer *JWTSigner, subject string) string {
+	t.Helper()
+	token, err := signer.Sign(signer.NewClaims(subject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
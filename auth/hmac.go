@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Signature"
+	TimestampHeader = "X-Timestamp"
+	KeyIDHeader     = "X-Key-Id"
+	NonceHeader     = "X-Nonce"
+)
+
+var (
+	ErrSignatureMissing = errors.New("auth: request signature missing")
+	ErrSignatureInvalid = errors.New("auth: request signature invalid")
+	ErrSignatureExpired = errors.New("auth: request timestamp outside allowed window")
+)
+
+// CanonicalString 待签名字符串：方法、路径（含查询）、时间戳、body 的 SHA-256，以换行分隔；
+// 请求带 X-Nonce 时在末尾再追加一行 nonce，见 canonicalRequest
+func CanonicalString(method, path, timestamp string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return strings.Join([]string{strings.ToUpper(method), path, timestamp, hex.EncodeToString(sum[:])}, "\n")
+}
+
+func canonicalRequest(r *http.Request, timestamp string, body []byte) string {
+	canonical := CanonicalString(r.Method, r.URL.RequestURI(), timestamp, body)
+	if nonce := r.Header.Get(NonceHeader); nonce != "" {
+		canonical += "\n" + nonce
+	}
+
+	return canonical
+}
+
+// Sign 计算 CanonicalString 的 HMAC-SHA256，十六进制编码
+func Sign(secret []byte, canonical string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest 供客户端使用，为请求写入时间戳、随机 nonce 和签名头，body 会被读出后还原
+func SignRequest(r *http.Request, keyID string, secret []byte) error {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	r.Header.Set(TimestampHeader, timestamp)
+	r.Header.Set(NonceHeader, nonce)
+	if keyID != "" {
+		r.Header.Set(KeyIDHeader, keyID)
+	}
+	r.Header.Set(SignatureHeader, Sign(secret, canonicalRequest(r, timestamp, body)))
+	return nil
+}
+
+// VerifyRequest 常量时间比较签名，并拒绝时间戳超出 window 的请求；
+// 只校验时间窗口，窗口内原样重放的请求仍会通过，需要防重放时再调用 VerifyNonce。
+// 返回读取到的 body，请求的 body 已被还原，可以再次读取
+func VerifyRequest(r *http.Request, secret []byte, window time.Duration) ([]byte, error) {
+	signature := r.Header.Get(SignatureHeader)
+	timestamp := r.Header.Get(TimestampHeader)
+	if signature == "" || timestamp == "" {
+		return nil, ErrSignatureMissing
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrSignatureInvalid
+	}
+	if skew := time.Since(time.Unix(unix, 0)); skew > window || skew < -window {
+		return nil, ErrSignatureExpired
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := Sign(secret, canonicalRequest(r, timestamp, body))
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return nil, ErrSignatureInvalid
+	}
+
+	return body, nil
+}
+
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("secret")
+
+func signedRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+	r := httptest.NewRequest("POST", "/orders?id=1", strings.NewReader(body))
+	if err := SignRequest(r, "k1", testSecret); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestVerifyRequest(t *testing.T) {
+	r := signedRequest(t, `{"amount":1}`)
+	if r.Header.Get(NonceHeader) == "" {
+		t.Fatal("SignRequest did not set a nonce")
+	}
+
+	body, err := VerifyRequest(r, testSecret, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"amount":1}` {
+		t.Fatalf("body = %q", body)
+	}
+
+	// body 已被还原，后续处理仍可读取
+	restored, _ := io.ReadAll(r.Body)
+	if string(restored) != `{"amount":1}` {
+		t.Fatalf("restored body = %q", restored)
+	}
+}
+
+func TestVerifyRequestWithoutNonce(t *testing.T) {
+	// 不带 nonce 的客户端按 CanonicalString 签名
+	r := httptest.NewRequest("GET", "/orders", nil)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	r.Header.Set(TimestampHeader, timestamp)
+	r.Header.Set(SignatureHeader, Sign(testSecret, CanonicalString("GET", "/orders", timestamp, nil)))
+
+	if _, err := VerifyRequest(r, testSecret, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyRequestRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(r *http.Request)
+		secret []byte
+		want   error
+	}{
+		{name: "wrong secret", secret: []byte("other"), want: ErrSignatureInvalid},
+		{name: "missing signature", tamper: func(r *http.Request) { r.Header.Del(SignatureHeader) }, want: ErrSignatureMissing},
+		{name: "path", tamper: func(r *http.Request) { r.URL.RawQuery = "id=2" }, want: ErrSignatureInvalid},
+		{name: "method", tamper: func(r *http.Request) { r.Method = "PUT" }, want: ErrSignatureInvalid},
+		{name: "body", tamper: func(r *http.Request) { r.Body = io.NopCloser(strings.NewReader(`{"amount":100}`)) }, want: ErrSignatureInvalid},
+		{name: "nonce", tamper: func(r *http.Request) { r.Header.Set(NonceHeader, "other") }, want: ErrSignatureInvalid},
+		{name: "nonce stripped", tamper: func(r *http.Request) { r.Header.Del(NonceHeader) }, want: ErrSignatureInvalid},
+		{name: "bad timestamp", tamper: func(r *http.Request) { r.Header.Set(TimestampHeader, "now") }, want: ErrSignatureInvalid},
+		{
+			name: "expired",
+			tamper: func(r *http.Request) {
+				r.Header.Set(TimestampHeader, strconv.FormatInt(time.Now().Add(-2*time.Minute).Unix(), 10))
+			},
+			want: ErrSignatureExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := signedRequest(t, `{"amount":1}`)
+			if tt.tamper != nil {
+				tt.tamper(r)
+			}
+			secret := testSecret
+			if tt.secret != nil {
+				secret = tt.secret
+			}
+
+			if _, err := VerifyRequest(r, secret, time.Minute); !errors.Is(err, tt.want) {
+				t.Fatalf("VerifyRequest() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyNonce(t *testing.T) {
+	store := NewMemoryNonceStore()
+	r := signedRequest(t, "")
+
+	if err := VerifyNonce(r, store, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyNonce(r, store, time.Minute); !errors.Is(err, ErrSignatureReplayed) {
+		t.Fatalf("replayed nonce: %v, want ErrSignatureReplayed", err)
+	}
+
+	// 不同密钥的同一个 nonce 互不影响
+	r.Header.Set(KeyIDHeader, "k2")
+	if err := VerifyNonce(r, store, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	r.Header.Del(NonceHeader)
+	if err := VerifyNonce(r, store, time.Minute); !errors.Is(err, ErrNonceMissing) {
+		t.Fatalf("missing nonce: %v, want ErrNonceMissing", err)
+	}
+}
+
+func TestMemoryNonceStoreExpires(t *testing.T) {
+	store := NewMemoryNonceStore()
+	ctx := context.Background()
+
+	if ok, _ := store.Use(ctx, "n", time.Now().Add(-time.Second)); !ok {
+		t.Fatal("first use rejected")
+	}
+	if ok, _ := store.Use(ctx, "n", time.Now().Add(time.Minute)); !ok {
+		t.Fatal("expired nonce not reusable")
+	}
+	if ok, _ := store.Use(ctx, "n", time.Now().Add(time.Minute)); ok {
+		t.Fatal("live nonce reused")
+	}
+}
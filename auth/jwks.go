@@ -0,0 +1,340 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWKSPath 公开密钥集的约定路径
+const JWKSPath = "/.well-known/jwks.json"
+
+// JWK RFC 7517 定义的单个公钥
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK 把公钥（或私钥对应的公钥）转换为 JWK，对称密钥不能公开
+func NewJWK(kid string, key any) (JWK, error) {
+	encode := base64.RawURLEncoding.EncodeToString
+	jwk := JWK{Kid: kid, Use: "sig"}
+
+	switch k := publicKey(key).(type) {
+	case *rsa.PublicKey:
+		// RSA 密钥可用于 RS256/RS384/RS512，默认声明为 RS256，JWTSigner.JWK 使用签发器实际的算法
+		jwk.Kty, jwk.Alg = "RSA", RS256
+		jwk.N = encode(k.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(k.E)).Bytes())
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return JWK{}, fmt.Errorf("%w: unsupported curve %s", ErrInvalidKey, k.Curve.Params().Name)
+		}
+		jwk.Kty, jwk.Crv, jwk.Alg = "EC", "P-256", ES256
+		jwk.X = encode(k.X.FillBytes(make([]byte, 32)))
+		jwk.Y = encode(k.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		jwk.Kty, jwk.Crv, jwk.Alg = "OKP", "Ed25519", EdDSA
+		jwk.X = encode(k)
+	default:
+		return JWK{}, fmt.Errorf("%w: %T can not be published as JWK", ErrInvalidKey, key)
+	}
+
+	return jwk, nil
+}
+
+// PublicKey 把 JWK 还原为公钥
+func (k JWK) PublicKey() (any, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("%w: unsupported curve %s", ErrInvalidKey, k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("%w: unsupported curve %s", ErrInvalidKey, k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, ErrInvalidKey
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type %q", ErrInvalidKey, k.Kty)
+	}
+}
+
+// JWKS 导出密钥集中所有可公开的密钥，HMAC 密钥会被跳过
+func (ks *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0)}
+	for _, kid := range ks.KeyIDs() {
+		key, ok := ks.Key(kid)
+		if !ok {
+			continue
+		}
+		if jwk, err := NewJWK(kid, key); err == nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+// JWK 导出签发器的公钥，alg 与签发 token 时使用的算法一致，HMAC 签发器返回 ErrInvalidKey
+func (s *JWTSigner) JWK() (JWK, error) {
+	jwk, err := NewJWK(s.keyID, s.key)
+	if err != nil {
+		return JWK{}, err
+	}
+	jwk.Alg = s.method.Alg()
+
+	return jwk, nil
+}
+
+// JWKSHandler 以 JSON 输出密钥集，通常挂载在 JWKSPath
+func JWKSHandler(ks *KeySet) http.Handler {
+	return jwksHandler(ks.JWKS)
+}
+
+// SignerJWKSHandler 以 JSON 输出签发器的公钥，轮换期间可同时传入新旧签发器，HMAC 签发器会被跳过
+func SignerJWKSHandler(signers ...*JWTSigner) http.Handler {
+	set := JWKSet{Keys: make([]JWK, 0, len(signers))}
+	for _, signer := range signers {
+		if jwk, err := signer.JWK(); err == nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return jwksHandler(func() JWKSet { return set })
+}
+
+func jwksHandler(jwks func() JWKSet) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_ = json.NewEncoder(w).Encode(jwks())
+	})
+}
+
+// RemoteJWKS 拉取并缓存远端 JWKS，过期后继续使用旧的密钥并在后台刷新，遇到未知 kid 时立即刷新，
+// 并发的刷新合并为一次请求，拉取过程中不阻塞其它校验
+type RemoteJWKS struct {
+	url         string
+	client      *http.Client
+	refresh     time.Duration
+	minInterval time.Duration
+	keys        *KeySet
+
+	mu        sync.Mutex
+	fetchedAt time.Time
+	attemptAt time.Time
+	lastErr   error
+	inflight  *fetchCall
+}
+
+// fetchCall 一次正在进行的拉取，结束后关闭 done
+type fetchCall struct {
+	done chan struct{}
+	err  error
+}
+
+type RemoteOption func(r *RemoteJWKS)
+
+func RemoteClient(client *http.Client) RemoteOption {
+	return func(r *RemoteJWKS) { r.client = client }
+}
+
+// RemoteRefresh 缓存有效期，默认 1 小时
+func RemoteRefresh(refresh time.Duration) RemoteOption {
+	return func(r *RemoteJWKS) { r.refresh = refresh }
+}
+
+// RemoteMinInterval 两次自动拉取的最小间隔，默认 1 分钟，避免未知 kid 或远端故障时频繁请求
+func RemoteMinInterval(interval time.Duration) RemoteOption {
+	return func(r *RemoteJWKS) { r.minInterval = interval }
+}
+
+func NewRemoteJWKS(url string, options ...RemoteOption) *RemoteJWKS {
+	r := &RemoteJWKS{
+		url:         url,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		refresh:     time.Hour,
+		minInterval: time.Minute,
+		keys:        NewKeySet(),
+	}
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
+}
+
+// Refresh 立即拉取远端密钥集，不受 RemoteMinInterval 限制
+func (r *RemoteJWKS) Refresh(ctx context.Context) error {
+	call := r.load(false)
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// load 返回正在进行的拉取，没有时发起新的拉取，throttle 为 true 且距上次拉取不足 minInterval 时返回 nil
+func (r *RemoteJWKS) load(throttle bool) *fetchCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.inflight != nil {
+		return r.inflight
+	}
+	if throttle && !r.attemptAt.IsZero() && time.Since(r.attemptAt) < r.minInterval {
+		return nil
+	}
+
+	call := &fetchCall{done: make(chan struct{})}
+	r.inflight = call
+	r.attemptAt = time.Now()
+
+	// 拉取不绑定调用方的 ctx，调用方取消不影响其它等待者，超时由 client 控制
+	go func() {
+		call.err = r.fetch(context.Background())
+
+		r.mu.Lock()
+		r.inflight = nil
+		r.lastErr = call.err
+		if call.err == nil {
+			r.fetchedAt = time.Now()
+		}
+		r.mu.Unlock()
+		close(call.done)
+	}()
+
+	return call
+}
+
+func (r *RemoteJWKS) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("auth: fetch jwks %s: unexpected status %d", r.url, resp.StatusCode)
+	}
+
+	var set JWKSet
+	if err = json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("auth: decode jwks %s: %w", r.url, err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.PublicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+
+	r.keys.replace(keys)
+	return nil
+}
+
+// ResolveKey 实现 KeyResolver
+func (r *RemoteJWKS) ResolveKey(token *jwt.Token) (any, error) {
+	r.mu.Lock()
+	fetched := !r.fetchedAt.IsZero()
+	stale := time.Since(r.fetchedAt) > r.refresh
+	r.mu.Unlock()
+
+	if !fetched {
+		// 还没有可用的密钥，只能等待拉取完成
+		if err := r.wait(r.load(true)); err != nil {
+			return nil, err
+		}
+	} else if stale {
+		r.load(true)
+	}
+
+	key, err := r.keys.ResolveKey(token)
+	if !errors.Is(err, ErrKeyNotFound) {
+		return key, err
+	}
+
+	// 未知 kid 可能是对方刚轮换了密钥
+	call := r.load(true)
+	if call == nil {
+		return nil, err
+	}
+	if err = r.wait(call); err != nil {
+		return nil, err
+	}
+
+	return r.keys.ResolveKey(token)
+}
+
+// wait 等待拉取结束，call 为 nil 时返回最近一次拉取的错误
+func (r *RemoteJWKS) wait(call *fetchCall) error {
+	if call == nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.lastErr
+	}
+
+	<-call.done
+	return call.err
+}
@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksServer 对外提供 keys 的 JWKS，gate 非空时请求会阻塞到 gate 关闭
+type jwksServer struct {
+	*httptest.Server
+	keys     *KeySet
+	requests atomic.Int32
+	gate     atomic.Pointer[chan struct{}]
+	status   atomic.Int32
+}
+
+func newJWKSServer(t *testing.T) *jwksServer {
+	s := &jwksServer{keys: NewKeySet()}
+	handler := JWKSHandler(s.keys)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if gate := s.gate.Load(); gate != nil {
+			<-*gate
+		}
+		if status := s.status.Load(); status != 0 {
+			w.WriteHeader(int(status))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) block() func() {
+	gate := make(chan struct{})
+	s.gate.Store(&gate)
+	return func() {
+		s.gate.Store(nil)
+		close(gate)
+	}
+}
+
+func verify(v *JWTVerifier, token string) error {
+	_, err := v.Verify(token, &jwt.RegisteredClaims{})
+	return err
+}
+
+func TestRemoteJWKSVerify(t *testing.T) {
+	server := newJWKSServer(t)
+	server.keys.Add("k1", &testRSAKey(t, 0).PublicKey)
+	signer := testSigner(t, RS256, testRSAKey(t, 0), SignerKeyID("k1"))
+
+	v := NewJWTVerifier(NewRemoteJWKS(server.URL))
+	for range 3 {
+		if err := verify(v, testToken(t, signer, "alice")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := server.requests.Load(); n != 1 {
+		t.Fatalf("fetched JWKS %d times, want 1", n)
+	}
+
+	other := testSigner(t, RS256, testRSAKey(t, 1), SignerKeyID("k1"))
+	if err := verify(v, testToken(t, other, "mallory")); err == nil {
+		t.Fatal("token signed by an unknown key was accepted")
+	}
+}
+
+func TestRemoteJWKSUnknownKidRefetches(t *testing.T) {
+	server := newJWKSServer(t)
+	server.keys.Add("k1", &testRSAKey(t, 0).PublicKey)
+
+	remote := NewRemoteJWKS(server.URL, RemoteMinInterval(0))
+	v := NewJWTVerifier(remote)
+	if err := verify(v, testToken(t, testSigner(t, RS256, testRSAKey(t, 0), SignerKeyID("k1")), "alice")); err != nil {
+		t.Fatal(err)
+	}
+
+	// 对方轮换密钥后，新 kid 触发一次拉取
+	server.keys.Add("k2", &testRSAKey(t, 1).PublicKey)
+	if err := verify(v, testToken(t, testSigner(t, RS256, testRSAKey(t, 1), SignerKeyID("k2")), "alice")); err != nil {
+		t.Fatal(err)
+	}
+	if n := server.requests.Load(); n != 2 {
+		t.Fatalf("fetched JWKS %d times, want 2", n)
+	}
+}
+
+func TestRemoteJWKSUnknownKidIsThrottled(t *testing.T) {
+	server := newJWKSServer(t)
+	server.keys.Add("k1", &testRSAKey(t, 0).PublicKey)
+
+	v := NewJWTVerifier(NewRemoteJWKS(server.URL, RemoteMinInterval(time.Hour)))
+	token := testToken(t, testSigner(t, RS256, testRSAKey(t, 1), SignerKeyID("unknown")), "mallory")
+	for range 5 {
+		if err := verify(v, token); err == nil {
+			t.Fatal("token with unknown kid was accepted")
+		}
+	}
+	if n := server.requests.Load(); n != 1 {
+		t.Fatalf("fetched JWKS %d times, want 1", n)
+	}
+}
+
+func TestRemoteJWKSServesStaleKeysWhileRefreshing(t *testing.T) {
+	server := newJWKSServer(t)
+	server.keys.Add("k1", &testRSAKey(t, 0).PublicKey)
+	token := testToken(t, testSigner(t, RS256, testRSAKey(t, 0), SignerKeyID("k1")), "alice")
+
+	remote := NewRemoteJWKS(server.URL, RemoteRefresh(time.Nanosecond), RemoteMinInterval(0))
+	v := NewJWTVerifier(remote)
+	if err := verify(v, token); err != nil {
+		t.Fatal(err)
+	}
+
+	release := server.block()
+	defer release()
+
+	done := make(chan error, 10)
+	for range 10 {
+		go func() { done <- verify(v, token) }()
+	}
+	for range 10 {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("verification blocked behind a JWKS refresh")
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for server.requests.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := server.requests.Load(); n != 2 {
+		t.Fatalf("fetched JWKS %d times, want 2 (one background refresh)", n)
+	}
+}
+
+func TestRemoteJWKSConcurrentFirstFetch(t *testing.T) {
+	server := newJWKSServer(t)
+	server.keys.Add("k1", &testRSAKey(t, 0).PublicKey)
+	token := testToken(t, testSigner(t, RS256, testRSAKey(t, 0), SignerKeyID("k1")), "alice")
+	release := server.block()
+
+	v := NewJWTVerifier(NewRemoteJWKS(server.URL))
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- verify(v, token)
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	release()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := server.requests.Load(); n != 1 {
+		t.Fatalf("fetched JWKS %d times, want 1", n)
+	}
+}
+
+func TestRemoteJWKSFetchError(t *testing.T) {
+	server := newJWKSServer(t)
+	server.status.Store(http.StatusInternalServerError)
+
+	remote := NewRemoteJWKS(server.URL)
+	if err := remote.Refresh(context.Background()); err == nil {
+		t.Fatal("Refresh() succeeded against a failing server")
+	}
+
+	token := testToken(t, testSigner(t, RS256, testRSAKey(t, 0), SignerKeyID("k1")), "alice")
+	if err := verify(NewJWTVerifier(remote), token); err == nil {
+		t.Fatal("token verified without any keys")
+	}
+}
+
+func TestJWKRoundTrip(t *testing.T) {
+	jwk, err := NewJWK("k1", testRSAKey(t, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := jwk.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !testRSAKey(t, 0).PublicKey.Equal(key) {
+		t.Fatal("round-tripped key does not match")
+	}
+	if jwk.Alg != RS256 || jwk.Use != "sig" {
+		t.Fatalf("RSA JWK alg = %q, use = %q", jwk.Alg, jwk.Use)
+	}
+
+	if _, err = NewJWK("hs", []byte("secret")); err == nil {
+		t.Fatal("symmetric key exported as JWK")
+	}
+}
+
+func TestSignerJWKSHandler(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	signers := []*JWTSigner{
+		testSigner(t, RS384, testRSAKey(t, 0), SignerKeyID("rsa")),
+		testSigner(t, ES256, ecKey, SignerKeyID("ec")),
+		testSigner(t, EdDSA, edKey, SignerKeyID("ed")),
+		testSigner(t, HS256, []byte("secret"), SignerKeyID("hs")),
+	}
+
+	rec := httptest.NewRecorder()
+	SignerJWKSHandler(signers...).ServeHTTP(rec, httptest.NewRequest("GET", JWKSPath, nil))
+	var set JWKSet
+	if err := json.Unmarshal(rec.Body.Bytes(), &set); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"rsa": RS384, "ec": ES256, "ed": EdDSA}
+	if len(set.Keys) != len(want) {
+		t.Fatalf("published %d keys, want %d (HMAC skipped)", len(set.Keys), len(want))
+	}
+	for _, jwk := range set.Keys {
+		if jwk.Alg != want[jwk.Kid] || jwk.Use != "sig" {
+			t.Errorf("JWK %s alg = %q, use = %q, want %q sig", jwk.Kid, jwk.Alg, jwk.Use, want[jwk.Kid])
+		}
+	}
+
+	// 远端使用签发器发布的密钥校验 token
+	srv := httptest.NewServer(SignerJWKSHandler(signers[0]))
+	defer srv.Close()
+	v := NewJWTVerifier(NewRemoteJWKS(srv.URL))
+	if err := verify(v, testToken(t, signers[0], "alice")); err != nil {
+		t.Fatal(err)
+	}
+}
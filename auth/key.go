@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+const (
+	RS256 = "RS256"
+	RS384 = "RS384"
+	RS512 = "RS512"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+	HS256 = "HS256"
+)
+
+// Algorithms 支持的签名算法
+var Algorithms = []string{RS256, RS384, RS512, ES256, EdDSA, HS256}
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("auth: unsupported signing algorithm")
+	ErrInvalidKey           = errors.New("auth: key does not match signing algorithm")
+	ErrInvalidPEM           = errors.New("auth: invalid PEM data")
+)
+
+// ParsePrivateKeyPEM 解析 PKCS#1、PKCS#8 或 SEC 1 格式的私钥
+func ParsePrivateKeyPEM(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown private key type %q", ErrInvalidPEM, block.Type)
+}
+
+// ParsePublicKeyPEM 解析 PKIX、PKCS#1 格式的公钥或证书中的公钥
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+		return cert.PublicKey, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown public key type %q", ErrInvalidPEM, block.Type)
+}
+
+// publicKey 私钥返回对应公钥，公钥和 HMAC 密钥原样返回
+func publicKey(key any) any {
+	if signer, ok := key.(crypto.Signer); ok {
+		return signer.Public()
+	}
+
+	return key
+}
+
+func checkSigningKey(alg string, key any) error {
+	switch alg {
+	case RS256, RS384, RS512:
+		if _, ok := key.(*rsa.PrivateKey); !ok {
+			return fmt.Errorf("%w: %s requires *rsa.PrivateKey, got %T", ErrInvalidKey, alg, key)
+		}
+	case ES256:
+		k, ok := key.(*ecdsa.PrivateKey)
+		if !ok || k.Curve != elliptic.P256() {
+			return fmt.Errorf("%w: %s requires P-256 *ecdsa.PrivateKey, got %T", ErrInvalidKey, alg, key)
+		}
+	case EdDSA:
+		if _, ok := key.(ed25519.PrivateKey); !ok {
+			return fmt.Errorf("%w: %s requires ed25519.PrivateKey, got %T", ErrInvalidKey, alg, key)
+		}
+	case HS256:
+		k, ok := key.([]byte)
+		if !ok || len(k) == 0 {
+			return fmt.Errorf("%w: %s requires a non-empty []byte secret", ErrInvalidKey, alg)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, alg)
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrKeyNotFound = errors.New("auth: verification key not found")
+
+// KeySet 按 kid 管理多个同时有效的验签密钥
+type KeySet struct {
+	mu   sync.RWMutex
+	keys map[string]any
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]any)}
+}
+
+// Add 添加或替换密钥，传入私钥时只保存其公钥
+func (ks *KeySet) Add(kid string, key any) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[kid] = publicKey(key)
+}
+
+func (ks *KeySet) Remove(kid string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	delete(ks.keys, kid)
+}
+
+func (ks *KeySet) Key(kid string) (key any, ok bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	key, ok = ks.keys[kid]
+	return
+}
+
+// KeyIDs 返回排序后的 kid 列表
+func (ks *KeySet) KeyIDs() []string {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	ids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		ids = append(ids, kid)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// ResolveKey 按 token 头部的 kid 选择密钥，没有 kid 且只有一个密钥时使用该密钥
+func (ks *KeySet) ResolveKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if key, ok := ks.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: kid %q", ErrKeyNotFound, kid)
+}
+
+func (ks *KeySet) replace(keys map[string]any) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys = keys
+}
+
+// FileKeySet 从 PEM 文件加载密钥，只在文件变化时重新解析
+type FileKeySet struct {
+	*KeySet
+	dir   string
+	files map[string]string
+
+	mu     sync.Mutex
+	stats  map[string]fileStat
+	stop   chan struct{}
+	done   chan struct{}
+	closed bool
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// LoadKeyFiles 加载 kid -> 文件路径 指定的密钥
+func LoadKeyFiles(files map[string]string) (*FileKeySet, error) {
+	fks := &FileKeySet{KeySet: NewKeySet(), files: files}
+	if err := fks.Reload(); err != nil {
+		return nil, err
+	}
+
+	return fks, nil
+}
+
+// LoadKeyDir 加载目录下所有 .pem 文件，文件名（不含扩展名）作为 kid
+// 轮换时放入新密钥文件，旧 token 过期后再删除旧文件即可
+func LoadKeyDir(dir string) (*FileKeySet, error) {
+	fks := &FileKeySet{KeySet: NewKeySet(), dir: dir}
+	if err := fks.Reload(); err != nil {
+		return nil, err
+	}
+
+	return fks, nil
+}
+
+func (fks *FileKeySet) sources() (map[string]string, error) {
+	if fks.dir == "" {
+		return fks.files, nil
+	}
+
+	matches, err := filepath.Glob(filepath.Join(fks.dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string, len(matches))
+	for _, file := range matches {
+		files[strings.TrimSuffix(filepath.Base(file), ".pem")] = file
+	}
+	return files, nil
+}
+
+// Reload 重新读取有变化的文件，任一文件出错时保留原有密钥
+func (fks *FileKeySet) Reload() error {
+	fks.mu.Lock()
+	defer fks.mu.Unlock()
+
+	files, err := fks.sources()
+	if err != nil {
+		return err
+	}
+
+	changed := len(files) != len(fks.stats)
+	stats := make(map[string]fileStat, len(files))
+	keys := make(map[string]any, len(files))
+	for kid, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+
+		stat := fileStat{modTime: info.ModTime(), size: info.Size()}
+		stats[kid] = stat
+		if key, ok := fks.Key(kid); ok && fks.stats[kid] == stat {
+			keys[kid] = key
+			continue
+		}
+
+		key, err := loadKeyFile(file)
+		if err != nil {
+			return fmt.Errorf("auth: load key %q: %w", kid, err)
+		}
+		keys[kid] = key
+		changed = true
+	}
+
+	if changed {
+		fks.replace(keys)
+	}
+	fks.stats = stats
+	return nil
+}
+
+// Watch 每隔 interval 检查一次文件变化，重复调用只启动一个协程，Close 后停止，Close 之后调用不再启动
+func (fks *FileKeySet) Watch(interval time.Duration) {
+	fks.mu.Lock()
+	defer fks.mu.Unlock()
+	if fks.stop != nil || fks.closed {
+		return
+	}
+
+	fks.stop, fks.done = make(chan struct{}), make(chan struct{})
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := fks.Reload(); err != nil {
+					slog.Warn("auth: reload key files failed", slog.String("err", err.Error()))
+				}
+			}
+		}
+	}(fks.stop, fks.done)
+}
+
+// Close 停止 Watch 启动的协程并等待正在进行的重新加载结束，可以重复调用
+func (fks *FileKeySet) Close() {
+	fks.mu.Lock()
+	if !fks.closed && fks.stop != nil {
+		close(fks.stop)
+	}
+	fks.closed = true
+	done := fks.done
+	fks.mu.Unlock()
+
+	// 协程中的 Reload 需要获取 mu，在锁外等待
+	if done != nil {
+		<-done
+	}
+}
+
+func loadKeyFile(file string) (any, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := ParsePublicKeyPEM(data); err == nil {
+		return key, nil
+	}
+
+	return ParsePrivateKeyPEM(data)
+}
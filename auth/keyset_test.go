@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestKeySetResolvesByKid(t *testing.T) {
+	keys := NewKeySet()
+	keys.Add("2024", publicKey(testRSAKey(t, 0)))
+	keys.Add("2025", publicKey(testRSAKey(t, 1)))
+	verifier := NewJWTVerifier(keys)
+
+	for i, kid := range []string{"2024", "2025"} {
+		token := testToken(t, testSigner(t, RS256, testRSAKey(t, i), SignerKeyID(kid)), "user-1")
+		if _, err := verifier.Verify(token, &jwt.RegisteredClaims{}); err != nil {
+			t.Fatalf("kid %s: %v", kid, err)
+		}
+	}
+
+	keys.Remove("2024")
+	token := testToken(t, testSigner(t, RS256, testRSAKey(t, 0), SignerKeyID("2024")), "user-1")
+	if _, err := verifier.Verify(token, &jwt.RegisteredClaims{}); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("removed kid: %v, want ErrKeyNotFound", err)
+	}
+}
+
+func writePublicKey(t *testing.T, file string, key *rsa.PrivateKey) {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileKeySetWatch(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.pem")
+	writePublicKey(t, file, testRSAKey(t, 0))
+	keys, err := LoadKeyFiles(map[string]string{"app": file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys.Watch(5 * time.Millisecond)
+	keys.Watch(5 * time.Millisecond)
+
+	current := func() *rsa.PublicKey {
+		key, _ := keys.Key("app")
+		return key.(*rsa.PublicKey)
+	}
+
+	writePublicKey(t, file, testRSAKey(t, 1))
+	deadline := time.Now().Add(2 * time.Second)
+	for !current().Equal(&testRSAKey(t, 1).PublicKey) {
+		if time.Now().After(deadline) {
+			t.Fatal("changed key file was not reloaded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Close 之后不再重新加载，重复调用和再次 Watch 都不会出错
+	keys.Close()
+	keys.Close()
+	keys.Watch(5 * time.Millisecond)
+	writePublicKey(t, file, testRSAKey(t, 0))
+	time.Sleep(50 * time.Millisecond)
+	if !current().Equal(&testRSAKey(t, 1).PublicKey) {
+		t.Fatal("key reloaded after Close")
+	}
+}
+
+func TestFileKeySetCloseBeforeWatch(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.pem")
+	writePublicKey(t, file, testRSAKey(t, 0))
+	keys, err := LoadKeyFiles(map[string]string{"app": file})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys.Close()
+	keys.Watch(5 * time.Millisecond)
+	writePublicKey(t, file, testRSAKey(t, 1))
+	time.Sleep(50 * time.Millisecond)
+	if key, _ := keys.Key("app"); !key.(*rsa.PublicKey).Equal(&testRSAKey(t, 0).PublicKey) {
+		t.Fatal("Watch started after Close")
+	}
+}
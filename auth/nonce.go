@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNonceMissing      = errors.New("auth: request nonce missing")
+	ErrSignatureReplayed = errors.New("auth: request nonce already used")
+)
+
+// NonceStore 记录签名请求使用过的 nonce，expiresAt 之后记录可以清理
+type NonceStore interface {
+	// Use 记录 nonce，窗口内已经使用过时返回 false
+	Use(ctx context.Context, nonce string, expiresAt time.Time) (bool, error)
+}
+
+// MemoryNonceStore 进程内的 nonce 记录，多实例部署时请实现共享存储，如 Redis SET NX
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	items     map[string]time.Time
+	lastClean time.Time
+}
+
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{items: make(map[string]time.Time)}
+}
+
+func (s *MemoryNonceStore) Use(_ context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastClean) > time.Minute {
+		for n, exp := range s.items {
+			if now.After(exp) {
+				delete(s.items, n)
+			}
+		}
+		s.lastClean = now
+	}
+
+	if exp, ok := s.items[nonce]; ok && now.Before(exp) {
+		return false, nil
+	}
+
+	s.items[nonce] = expiresAt
+	return true, nil
+}
+
+// VerifyNonce 拒绝在时间窗口内重复使用的 nonce，需在 VerifyRequest 成功后调用，
+// 避免签名错误的请求占用 nonce；nonce 按 X-Key-Id 区分
+func VerifyNonce(r *http.Request, store NonceStore, window time.Duration) error {
+	nonce := r.Header.Get(NonceHeader)
+	if nonce == "" {
+		return ErrNonceMissing
+	}
+
+	// 时间戳前后各允许 window 的偏差，nonce 至少要记住 2*window
+	fresh, err := store.Use(r.Context(), r.Header.Get(KeyIDHeader)+":"+nonce, time.Now().Add(2*window))
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrSignatureReplayed
+	}
+
+	return nil
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
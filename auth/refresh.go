@@ -0,0 +1,305 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("auth: invalid refresh token")
+	ErrRefreshTokenExpired = errors.New("auth: refresh token expired")
+	ErrRefreshTokenRevoked = errors.New("auth: refresh token revoked")
+	ErrRefreshTokenReused  = errors.New("auth: refresh token reused, token family revoked")
+)
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// RefreshRecord 刷新令牌的服务端记录，ID 为令牌的哈希，不保存明文
+type RefreshRecord struct {
+	ID        string
+	FamilyID  string
+	Claims    Claims
+	AccessID  string
+	AccessExp time.Time
+	ExpiresAt time.Time
+	Used      bool
+	Revoked   bool
+}
+
+// RefreshStore 刷新令牌存储
+// MarkUsed 必须是原子操作：记录已吊销时返回 ErrRefreshTokenRevoked，已被使用过时返回 false，用于检测重放
+type RefreshStore interface {
+	Save(ctx context.Context, record *RefreshRecord) error
+	Get(ctx context.Context, id string) (*RefreshRecord, error)
+	MarkUsed(ctx context.Context, id string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) ([]*RefreshRecord, error)
+}
+
+type MemoryRefreshStore struct {
+	mu        sync.Mutex
+	records   map[string]*RefreshRecord
+	lastClean time.Time
+}
+
+func NewMemoryRefreshStore() *MemoryRefreshStore {
+	return &MemoryRefreshStore{records: make(map[string]*RefreshRecord)}
+}
+
+func (s *MemoryRefreshStore) Save(_ context.Context, record *RefreshRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastClean) > time.Minute {
+		for id, r := range s.records {
+			if now.After(r.ExpiresAt) {
+				delete(s.records, id)
+			}
+		}
+		s.lastClean = now
+	}
+
+	r := *record
+	s.records[r.ID] = &r
+	return nil
+}
+
+func (s *MemoryRefreshStore) Get(_ context.Context, id string) (*RefreshRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[id]
+	if !ok {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	record := *r
+	return &record, nil
+}
+
+func (s *MemoryRefreshStore) MarkUsed(_ context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[id]
+	if !ok {
+		return false, ErrRefreshTokenInvalid
+	}
+	if r.Revoked {
+		return false, ErrRefreshTokenRevoked
+	}
+	if r.Used {
+		return false, nil
+	}
+
+	r.Used = true
+	return true, nil
+}
+
+func (s *MemoryRefreshStore) RevokeFamily(_ context.Context, familyID string) ([]*RefreshRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var revoked []*RefreshRecord
+	for _, r := range s.records {
+		if r.FamilyID == familyID && !r.Revoked {
+			r.Revoked = true
+			record := *r
+			revoked = append(revoked, &record)
+		}
+	}
+
+	return revoked, nil
+}
+
+// TokenManager 签发 access/refresh 令牌对，刷新时轮换 refresh 令牌
+// 已使用过的 refresh 令牌再次出现时，整个令牌家族及其 access 令牌都会被吊销
+type TokenManager struct {
+	signer      *JWTSigner
+	refresh     RefreshStore
+	revocations RevocationStore
+	refreshTTL  time.Duration
+}
+
+type ManagerOption func(m *TokenManager)
+
+func ManagerRefreshStore(store RefreshStore) ManagerOption {
+	return func(m *TokenManager) { m.refresh = store }
+}
+
+func ManagerRevocationStore(store RevocationStore) ManagerOption {
+	return func(m *TokenManager) { m.revocations = store }
+}
+
+// ManagerRefreshTTL refresh 令牌有效期，默认 30 天
+func ManagerRefreshTTL(ttl time.Duration) ManagerOption {
+	return func(m *TokenManager) { m.refreshTTL = ttl }
+}
+
+func NewTokenManager(signer *JWTSigner, options ...ManagerOption) *TokenManager {
+	m := &TokenManager{
+		signer:      signer,
+		refresh:     NewMemoryRefreshStore(),
+		revocations: NewMemoryRevocationStore(),
+		refreshTTL:  30 * 24 * time.Hour,
+	}
+	for _, option := range options {
+		option(m)
+	}
+
+	return m
+}
+
+// Revocations 返回吊销列表，校验端通过 VerifierRevocation 使用
+func (m *TokenManager) Revocations() RevocationStore {
+	return m.revocations
+}
+
+// Issue 以 claims 为模板签发新的令牌对，claims.Subject 为用户标识
+func (m *TokenManager) Issue(ctx context.Context, claims Claims) (*TokenPair, error) {
+	return m.issue(ctx, claims, newID())
+}
+
+// Refresh 校验并轮换 refresh 令牌
+func (m *TokenManager) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	id := hashToken(refreshToken)
+	record, err := m.refresh.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.Revoked {
+		return nil, ErrRefreshTokenRevoked
+	}
+	if time.Now().After(record.ExpiresAt) {
+		return nil, ErrRefreshTokenExpired
+	}
+
+	first, err := m.refresh.MarkUsed(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		if err = m.revokeFamily(ctx, record.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	pair, err := m.issue(ctx, record.Claims, record.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 签发期间家族可能被并发的重放检测吊销，新记录保存之前的吊销不会覆盖它，需要再吊销一次
+	if record, err = m.refresh.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	if record.Revoked {
+		if err = m.revokeFamily(ctx, record.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenRevoked
+	}
+
+	return pair, nil
+}
+
+// Logout 吊销 refresh 令牌所在的家族
+func (m *TokenManager) Logout(ctx context.Context, refreshToken string) error {
+	record, err := m.refresh.Get(ctx, hashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+
+	return m.revokeFamily(ctx, record.FamilyID)
+}
+
+// Revoke 吊销单个 access 令牌
+func (m *TokenManager) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	return m.revocations.Revoke(ctx, jti, expiresAt)
+}
+
+func (m *TokenManager) issue(ctx context.Context, claims Claims, familyID string) (*TokenPair, error) {
+	claims.RegisteredClaims = m.signer.NewClaims(claims.Subject)
+	accessToken, err := m.signer.Sign(&claims)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := &RefreshRecord{
+		ID:        hashToken(refreshToken),
+		FamilyID:  familyID,
+		Claims:    claims,
+		AccessID:  claims.ID,
+		ExpiresAt: now.Add(m.refreshTTL),
+	}
+	if claims.ExpiresAt != nil {
+		record.AccessExp = claims.ExpiresAt.Time
+	}
+	if err = m.refresh.Save(ctx, record); err != nil {
+		return nil, err
+	}
+
+	pair := &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+	}
+	if !record.AccessExp.IsZero() {
+		pair.ExpiresIn = int64(record.AccessExp.Sub(now).Seconds())
+	}
+
+	return pair, nil
+}
+
+func (m *TokenManager) revokeFamily(ctx context.Context, familyID string) error {
+	records, err := m.refresh.RevokeFamily(ctx, familyID)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		if r.AccessID == "" || (!r.AccessExp.IsZero() && time.Now().After(r.AccessExp)) {
+			continue
+		}
+
+		expiresAt := r.AccessExp
+		if expiresAt.IsZero() {
+			expiresAt = r.ExpiresAt
+		}
+		if err = m.revocations.Revoke(ctx, r.AccessID, expiresAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
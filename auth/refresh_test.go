@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testManager(t *testing.T, options ...ManagerOption) (*TokenManager, *JWTVerifier) {
+	t.Helper()
+	key := []byte("secret")
+	m := NewTokenManager(testSigner(t, HS256, key), options...)
+	return m, NewJWTVerifier(StaticKey(key), VerifierRevocation(m.Revocations()))
+}
+
+func TestTokenManagerRotation(t *testing.T) {
+	m, verifier := testManager(t)
+	ctx := t.Context()
+
+	first, err := m.Issue(ctx, Claims{Roles: []string{"admin"}, RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.TokenType != "Bearer" || first.ExpiresIn <= 0 {
+		t.Fatalf("pair = %+v", first)
+	}
+
+	second, err := m.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+
+	claims, err := VerifyClaims[Claims](verifier, second.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "user-1" || len(claims.Roles) != 1 {
+		t.Fatalf("refreshed claims = %+v", claims)
+	}
+}
+
+func TestTokenManagerReuseRevokesFamily(t *testing.T) {
+	m, verifier := testManager(t)
+	ctx := t.Context()
+
+	first, _ := m.Issue(ctx, Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1"}})
+	second, err := m.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := m.Issue(ctx, Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user-2"}})
+
+	// 旧的 refresh 令牌被再次使用，视为泄露
+	if _, err = m.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reuse: %v, want ErrRefreshTokenReused", err)
+	}
+	if _, err = m.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrRefreshTokenRevoked) {
+		t.Fatalf("latest refresh token: %v, want ErrRefreshTokenRevoked", err)
+	}
+	for name, token := range map[string]string{"first": first.AccessToken, "second": second.AccessToken} {
+		if _, err = VerifyClaims[Claims](verifier, token); !errors.Is(err, ErrTokenRevoked) {
+			t.Errorf("%s access token: %v, want ErrTokenRevoked", name, err)
+		}
+	}
+
+	// 其他家族不受影响
+	if _, err = VerifyClaims[Claims](verifier, other.AccessToken); err != nil {
+		t.Fatalf("other family access token: %v", err)
+	}
+	if _, err = m.Refresh(ctx, other.RefreshToken); err != nil {
+		t.Fatalf("other family refresh: %v", err)
+	}
+}
+
+func TestTokenManagerLogout(t *testing.T) {
+	m, verifier := testManager(t)
+	ctx := t.Context()
+
+	pair, _ := m.Issue(ctx, Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1"}})
+	if err := m.Logout(ctx, pair.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrRefreshTokenRevoked) {
+		t.Fatalf("refresh after logout: %v, want ErrRefreshTokenRevoked", err)
+	}
+	if _, err := VerifyClaims[Claims](verifier, pair.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("access after logout: %v, want ErrTokenRevoked", err)
+	}
+}
+
+func TestTokenManagerRejects(t *testing.T) {
+	m, _ := testManager(t, ManagerRefreshTTL(-time.Second))
+	ctx := t.Context()
+
+	if _, err := m.Refresh(ctx, "unknown"); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("unknown token: %v, want ErrRefreshTokenInvalid", err)
+	}
+
+	pair, _ := m.Issue(ctx, Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1"}})
+	if _, err := m.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrRefreshTokenExpired) {
+		t.Fatalf("expired token: %v, want ErrRefreshTokenExpired", err)
+	}
+}
+
+func TestTokenManagerConcurrentReuse(t *testing.T) {
+	m, verifier := testManager(t)
+	ctx := t.Context()
+	pair, _ := m.Issue(ctx, Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1"}})
+
+	const n = 16
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		issued []*TokenPair
+		reused int
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			next, err := m.Refresh(ctx, pair.RefreshToken)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				issued = append(issued, next)
+			case errors.Is(err, ErrRefreshTokenReused):
+				reused++
+			case !errors.Is(err, ErrRefreshTokenRevoked):
+				t.Errorf("Refresh() = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(issued) > 1 || reused == 0 {
+		t.Fatalf("issued %d pairs, detected %d reuses", len(issued), reused)
+	}
+	// 并发重放吊销了整个家族，包括在此期间签发的令牌
+	for _, p := range issued {
+		if _, err := m.Refresh(ctx, p.RefreshToken); !errors.Is(err, ErrRefreshTokenRevoked) {
+			t.Errorf("refresh token issued during reuse: %v, want ErrRefreshTokenRevoked", err)
+		}
+		if _, err := VerifyClaims[Claims](verifier, p.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+			t.Errorf("access token issued during reuse: %v, want ErrTokenRevoked", err)
+		}
+	}
+}
+
+// 吊销发生在 MarkUsed 之后、新令牌保存之前时，新令牌也必须失效
+func TestTokenManagerRevokedDuringRefresh(t *testing.T) {
+	store := &racingStore{MemoryRefreshStore: NewMemoryRefreshStore()}
+	m, verifier := testManager(t, ManagerRefreshStore(store))
+	ctx := t.Context()
+	pair, _ := m.Issue(ctx, Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1"}})
+
+	store.onMarkUsed = func(id string) {
+		record, _ := store.Get(ctx, id)
+		_ = m.revokeFamily(ctx, record.FamilyID)
+	}
+	if _, err := m.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrRefreshTokenRevoked) {
+		t.Fatalf("Refresh() = %v, want ErrRefreshTokenRevoked", err)
+	}
+
+	store.onMarkUsed = nil
+	store.mu.Lock()
+	for _, r := range store.records {
+		if !r.Revoked {
+			t.Errorf("record %s issued into a revoked family is still live", r.ID)
+		}
+	}
+	store.mu.Unlock()
+	if _, err := VerifyClaims[Claims](verifier, pair.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("access token: %v, want ErrTokenRevoked", err)
+	}
+}
+
+func TestMarkUsedRejectsRevoked(t *testing.T) {
+	store := NewMemoryRefreshStore()
+	ctx := t.Context()
+	_ = store.Save(ctx, &RefreshRecord{ID: "r1", FamilyID: "f1", ExpiresAt: time.Now().Add(time.Hour)})
+	_, _ = store.RevokeFamily(ctx, "f1")
+
+	if ok, err := store.MarkUsed(ctx, "r1"); ok || !errors.Is(err, ErrRefreshTokenRevoked) {
+		t.Fatalf("MarkUsed() = %v, %v, want ErrRefreshTokenRevoked", ok, err)
+	}
+}
+
+// racingStore 在 MarkUsed 成功后执行 onMarkUsed，模拟并发的吊销
+type racingStore struct {
+	*MemoryRefreshStore
+	onMarkUsed func(id string)
+}
+
+func (s *racingStore) MarkUsed(ctx context.Context, id string) (bool, error) {
+	ok, err := s.MemoryRefreshStore.MarkUsed(ctx, id)
+	if ok && s.onMarkUsed != nil {
+		s.onMarkUsed(id)
+	}
+	return ok, err
+}
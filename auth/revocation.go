@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrTokenRevoked = errors.New("auth: token has been revoked")
+
+// RevocationStore 按 jti 记录已吊销的 token，expiresAt 之后记录可以清理
+type RevocationStore interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// MemoryRevocationStore 进程内的吊销列表，多实例部署时请实现共享存储
+type MemoryRevocationStore struct {
+	mu        sync.RWMutex
+	items     map[string]time.Time
+	lastClean time.Time
+}
+
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{items: make(map[string]time.Time)}
+}
+
+func (s *MemoryRevocationStore) Revoke(_ context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastClean) > time.Minute {
+		for id, exp := range s.items {
+			if now.After(exp) {
+				delete(s.items, id)
+			}
+		}
+		s.lastClean = now
+	}
+
+	s.items[jti] = expiresAt
+	return nil
+}
+
+func (s *MemoryRevocationStore) IsRevoked(_ context.Context, jti string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.items[jti]
+	return ok, nil
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTSigner 使用内存中的密钥签发 token
+type JWTSigner struct {
+	method   jwt.SigningMethod
+	key      any
+	keyID    string
+	issuer   string
+	audience []string
+	expire   time.Duration
+}
+
+type SignerOption func(s *JWTSigner)
+
+// SignerKeyID 写入 token 头部的 kid，用于密钥轮换
+func SignerKeyID(kid string) SignerOption {
+	return func(s *JWTSigner) { s.keyID = kid }
+}
+
+func SignerIssuer(issuer string) SignerOption {
+	return func(s *JWTSigner) { s.issuer = issuer }
+}
+
+func SignerAudience(audience ...string) SignerOption {
+	return func(s *JWTSigner) { s.audience = audience }
+}
+
+func SignerExpire(expire time.Duration) SignerOption {
+	return func(s *JWTSigner) { s.expire = expire }
+}
+
+// NewJWTSigner alg 取值见 Algorithms，key 为对应算法的私钥，HS256 为 []byte
+func NewJWTSigner(alg string, key any, options ...SignerOption) (*JWTSigner, error) {
+	if err := checkSigningKey(alg, key); err != nil {
+		return nil, err
+	}
+
+	s := &JWTSigner{
+		method: jwt.GetSigningMethod(alg),
+		key:    key,
+		expire: time.Hour,
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s, nil
+}
+
+func (s *JWTSigner) Algorithm() string {
+	return s.method.Alg()
+}
+
+func (s *JWTSigner) KeyID() string {
+	return s.keyID
+}
+
+// NewClaims 按签发器配置生成标准声明，包含 iss、sub、aud、iat、nbf、exp、jti
+func (s *JWTSigner) NewClaims(subject string) jwt.RegisteredClaims {
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		ID:        newID(),
+		Issuer:    s.issuer,
+		Subject:   subject,
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+	}
+	if len(s.audience) > 0 {
+		claims.Audience = s.audience
+	}
+	if s.expire > 0 {
+		claims.ExpiresAt = jwt.NewNumericDate(now.Add(s.expire))
+	}
+
+	return claims
+}
+
+// Sign 签发任意声明，自定义声明可嵌入 jwt.RegisteredClaims 或使用 Claims
+func (s *JWTSigner) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.method, claims)
+	if s.keyID != "" {
+		token.Header["kid"] = s.keyID
+	}
+
+	return token.SignedString(s.key)
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyResolver 根据 token 头部选择验签密钥
+type KeyResolver interface {
+	ResolveKey(token *jwt.Token) (any, error)
+}
+
+type KeyResolverFunc func(token *jwt.Token) (any, error)
+
+func (f KeyResolverFunc) ResolveKey(token *jwt.Token) (any, error) {
+	return f(token)
+}
+
+// StaticKey 使用单个固定密钥验签，传入私钥时自动取其公钥
+func StaticKey(key any) KeyResolver {
+	key = publicKey(key)
+	return KeyResolverFunc(func(*jwt.Token) (any, error) {
+		return key, nil
+	})
+}
+
+// JWTVerifier 校验 token 签名和标准声明
+type JWTVerifier struct {
+	keys        KeyResolver
+	methods     []string
+	options     []jwt.ParserOption
+	revocations RevocationStore
+}
+
+type VerifierOption func(v *JWTVerifier)
+
+// VerifierMethods 限定允许的签名算法，默认为 Algorithms
+func VerifierMethods(algs ...string) VerifierOption {
+	return func(v *JWTVerifier) { v.methods = algs }
+}
+
+func VerifierIssuer(issuer string) VerifierOption {
+	return func(v *JWTVerifier) { v.options = append(v.options, jwt.WithIssuer(issuer)) }
+}
+
+func VerifierAudience(audience string) VerifierOption {
+	return func(v *JWTVerifier) { v.options = append(v.options, jwt.WithAudience(audience)) }
+}
+
+func VerifierSubject(subject string) VerifierOption {
+	return func(v *JWTVerifier) { v.options = append(v.options, jwt.WithSubject(subject)) }
+}
+
+func VerifierLeeway(leeway time.Duration) VerifierOption {
+	return func(v *JWTVerifier) { v.options = append(v.options, jwt.WithLeeway(leeway)) }
+}
+
+// VerifierExpirationRequired 拒绝没有 exp 的 token
+func VerifierExpirationRequired() VerifierOption {
+	return func(v *JWTVerifier) { v.options = append(v.options, jwt.WithExpirationRequired()) }
+}
+
+// VerifierRevocation 校验时检查 jti 是否已被吊销
+func VerifierRevocation(store RevocationStore) VerifierOption {
+	return func(v *JWTVerifier) { v.revocations = store }
+}
+
+func NewJWTVerifier(keys KeyResolver, options ...VerifierOption) *JWTVerifier {
+	v := &JWTVerifier{
+		keys:    keys,
+		methods: Algorithms,
+	}
+	for _, option := range options {
+		option(v)
+	}
+
+	return v
+}
+
+// Verify 校验 token 并把声明解析到 claims
+func (v *JWTVerifier) Verify(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return v.VerifyContext(context.Background(), tokenString, claims)
+}
+
+// VerifyContext 同 Verify，ctx 用于查询吊销列表
+func (v *JWTVerifier) VerifyContext(ctx context.Context, tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	options := append([]jwt.ParserOption{jwt.WithValidMethods(v.methods), jwt.WithIssuedAt()}, v.options...)
+	token, err := jwt.ParseWithClaims(tokenString, claims, v.keys.ResolveKey, options...)
+	if err != nil {
+		return token, err
+	}
+
+	if v.revocations != nil {
+		jti, err := tokenID(token)
+		if err != nil {
+			return token, err
+		}
+		if jti != "" {
+			revoked, err := v.revocations.IsRevoked(ctx, jti)
+			if err != nil {
+				return token, err
+			}
+			if revoked {
+				return token, ErrTokenRevoked
+			}
+		}
+	}
+
+	return token, nil
+}
+
+// tokenID 从载荷中读取 jti，不要求声明类型嵌入 jwt.RegisteredClaims
+func tokenID(token *jwt.Token) (string, error) {
+	parts := strings.Split(token.Raw, ".")
+	if len(parts) != 3 {
+		return "", jwt.ErrTokenMalformed
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", err
+	}
+
+	var claims struct {
+		ID string `json:"jti"`
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return "", err
+	}
+
+	return claims.ID, nil
+}
+
+// VerifyClaims 校验 token 并返回指定类型的声明
+func VerifyClaims[T any, P interface {
+	*T
+	jwt.Claims
+}](v *JWTVerifier, tokenString string) (*T, error) {
+	claims := P(new(T))
+	if _, err := v.Verify(tokenString, claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestSignVerifyAlgorithms(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := map[string]any{
+		RS256: testRSAKey(t, 0),
+		RS384: testRSAKey(t, 0),
+		RS512: testRSAKey(t, 0),
+		ES256: ecKey,
+		EdDSA: edKey,
+		HS256: []byte("secret"),
+	}
+	for _, alg := range Algorithms {
+		t.Run(alg, func(t *testing.T) {
+			signer := testSigner(t, alg, keys[alg], SignerIssuer("water"), SignerAudience("api"))
+			token := testToken(t, signer, "user-1")
+
+			verifier := NewJWTVerifier(StaticKey(keys[alg]), VerifierIssuer("water"), VerifierAudience("api"))
+			claims, err := VerifyClaims[jwt.RegisteredClaims](verifier, token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Subject != "user-1" || claims.ID == "" {
+				t.Fatalf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestNewJWTSignerRejectsMismatchedKey(t *testing.T) {
+	if _, err := NewJWTSigner(RS256, []byte("secret")); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("RS256 with []byte: %v, want ErrInvalidKey", err)
+	}
+	if _, err := NewJWTSigner(HS256, []byte{}); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("HS256 with empty secret: %v, want ErrInvalidKey", err)
+	}
+	if _, err := NewJWTSigner("none", nil); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Fatalf("none: %v, want ErrUnsupportedAlgorithm", err)
+	}
+}
+
+func TestVerifierRejects(t *testing.T) {
+	key := testRSAKey(t, 0)
+	signer := testSigner(t, RS256, key, SignerIssuer("water"), SignerAudience("api"))
+	verifier := NewJWTVerifier(StaticKey(key),
+		VerifierMethods(RS256),
+		VerifierIssuer("water"),
+		VerifierAudience("api"),
+	)
+
+	expired := signer.NewClaims("user-1")
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+
+	tests := map[string]struct {
+		token string
+		want  error
+	}{
+		"other key":      {token: testToken(t, testSigner(t, RS256, testRSAKey(t, 1), SignerIssuer("water"), SignerAudience("api")), "user-1"), want: jwt.ErrTokenSignatureInvalid},
+		"other issuer":   {token: testToken(t, testSigner(t, RS256, key, SignerIssuer("evil"), SignerAudience("api")), "user-1"), want: jwt.ErrTokenInvalidIssuer},
+		"other audience": {token: testToken(t, testSigner(t, RS256, key, SignerIssuer("water"), SignerAudience("web")), "user-1"), want: jwt.ErrTokenInvalidAudience},
+		"disallowed alg": {token: testToken(t, testSigner(t, RS512, key, SignerIssuer("water"), SignerAudience("api")), "user-1"), want: jwt.ErrTokenSignatureInvalid},
+		"expired":        {token: mustSign(t, signer, expired), want: jwt.ErrTokenExpired},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := verifier.Verify(tt.token, &jwt.RegisteredClaims{}); !errors.Is(err, tt.want) {
+				t.Fatalf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// 使用公钥作为 HS256 密钥伪造的 token 必须被拒绝
+func TestVerifierRejectsAlgorithmConfusion(t *testing.T) {
+	key := testRSAKey(t, 0)
+	forged := testToken(t, testSigner(t, HS256, []byte("public key bytes")), "admin")
+
+	verifier := NewJWTVerifier(StaticKey(key), VerifierMethods(RS256))
+	if _, err := verifier.Verify(forged, &jwt.RegisteredClaims{}); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Fatalf("Verify() = %v, want ErrTokenSignatureInvalid", err)
+	}
+}
+
+func TestVerifierRevocation(t *testing.T) {
+	key := testRSAKey(t, 0)
+	signer := testSigner(t, RS256, key)
+	store := NewMemoryRevocationStore()
+	verifier := NewJWTVerifier(StaticKey(key), VerifierRevocation(store))
+
+	claims := signer.NewClaims("user-1")
+	token := mustSign(t, signer, claims)
+	if _, err := verifier.Verify(token, &jwt.RegisteredClaims{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = store.Revoke(t.Context(), claims.ID, claims.ExpiresAt.Time)
+	if _, err := verifier.Verify(token, &jwt.RegisteredClaims{}); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("Verify() = %v, want ErrTokenRevoked", err)
+	}
+}
+
+func mustSign(t *testing.T, signer *JWTSigner, claims jwt.Claims) string {
+	t.Helper()
+	token, err := signer.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
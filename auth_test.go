@@ -0,0 +1,177 @@
+package water
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-water/water/auth"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKeyPair 生成 RSA 密钥对并写入 PEM 文件，返回私钥路径和公钥路径
+func writeKeyPair(t *testing.T, dir, name string) (string, string, *rsa.PrivateKey) {
+	t.Helper()
+	// 测试结束时停止 ParseFromRequest 监听公钥文件的协程
+	t.Cleanup(CloseKeyFiles)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	private := filepath.Join(dir, name+".key")
+	public := filepath.Join(dir, name+".pem")
+	if err = os.WriteFile(private, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(public, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return private, public, key
+}
+
+func TestParseFromRequestRoundTrip(t *testing.T) {
+	private, public, _ := writeKeyPair(t, t.TempDir(), "app")
+
+	token, err := SetAuthToken("user-1", "water", private, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	user, issuer, signature, err := ParseFromRequest(req, public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user != "user-1" || issuer != "water" || signature == "" {
+		t.Fatalf("ParseFromRequest() = %q, %q, %q", user, issuer, signature)
+	}
+
+	// 旧版客户端通过 WebSocket 子协议直接发送 token
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Sec-WebSocket-Protocol", token)
+	if user, _, _, err = ParseFromRequest(req, public); err != nil || user != "user-1" {
+		t.Fatalf("ParseFromRequest() from subprotocol = %q, %v", user, err)
+	}
+}
+
+func TestParseFromRequestAcceptsAnyKid(t *testing.T) {
+	_, public, key := writeKeyPair(t, t.TempDir(), "app")
+
+	signer, err := auth.NewJWTSigner(auth.RS256, key, auth.SignerKeyID("2024-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := signer.Sign(jwt.RegisteredClaims{ID: "user-1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if user, _, _, err := ParseFromRequest(req, public); err != nil || user != "user-1" {
+		t.Fatalf("ParseFromRequest() with kid = %q, %v", user, err)
+	}
+}
+
+func TestParseFromRequestRejects(t *testing.T) {
+	dir := t.TempDir()
+	private, public, _ := writeKeyPair(t, dir, "app")
+	otherPrivate, _, _ := writeKeyPair(t, dir, "other")
+
+	expired, err := SetAuthToken("user-1", "water", private, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := SetAuthToken("user-1", "water", otherPrivate, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"expired": expired, "forged": forged, "garbage": "a.b.c"} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if _, _, _, err := ParseFromRequest(req, public); err == nil {
+			t.Errorf("%s token accepted", name)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	if _, _, _, err := ParseFromRequest(req, public); err == nil {
+		t.Error("request without token accepted")
+	}
+}
+
+func TestParseFromRequestReloadsRotatedKey(t *testing.T) {
+	old := keyReloadInterval
+	keyReloadInterval = 10 * time.Millisecond
+	defer func() { keyReloadInterval = old }()
+
+	dir := t.TempDir()
+	oldPrivate, public, _ := writeKeyPair(t, dir, "app")
+	oldToken, _ := SetAuthToken("user-1", "water", oldPrivate, time.Hour)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+oldToken)
+	if _, _, _, err := ParseFromRequest(req, public); err != nil {
+		t.Fatal(err)
+	}
+
+	// 覆盖公钥文件后，新密钥签发的 token 在下次轮询后生效
+	time.Sleep(10 * time.Millisecond)
+	newPrivate, _, _ := writeKeyPair(t, dir, "app")
+	newToken, _ := SetAuthToken("user-1", "water", newPrivate, time.Hour)
+	req.Header.Set("Authorization", "Bearer "+newToken)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		_, _, _, err := ParseFromRequest(req, public)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("rotated key was not picked up: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCloseKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	oldPrivate, public, _ := writeKeyPair(t, dir, "app")
+	oldToken, _ := SetAuthToken("user-1", "water", oldPrivate, time.Hour)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+oldToken)
+	if _, _, _, err := ParseFromRequest(req, public); err != nil {
+		t.Fatal(err)
+	}
+
+	// Water.Shutdown 停止监听并清空缓存，之后按需重新加载公钥
+	if err := New().Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fileVerifiers.Load(public); ok {
+		t.Fatal("verifier still cached after Shutdown")
+	}
+
+	newPrivate, _, _ := writeKeyPair(t, dir, "app")
+	newToken, _ := SetAuthToken("user-1", "water", newPrivate, time.Hour)
+	req.Header.Set("Authorization", "Bearer "+newToken)
+	if _, _, _, err := ParseFromRequest(req, public); err != nil {
+		t.Fatalf("ParseFromRequest() after CloseKeyFiles = %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package water
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AuthorizationError 授权失败的结构化描述，errors.Is(err, ErrForbidden) 成立
+type AuthorizationError struct {
+	Policy   string   `json:"policy"`
+	Reason   string   `json:"reason"`
+	Required []string `json:"required,omitempty"`
+}
+
+func (e *AuthorizationError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrForbidden, e.Policy, e.Reason)
+}
+
+func (e *AuthorizationError) Unwrap() error {
+	return ErrForbidden
+}
+
+// Policy 授权策略，Middleware 中 req 为 nil，ServerOption 中为请求对象
+type Policy interface {
+	Name() string
+	Authorize(ctx context.Context, req any) error
+}
+
+type policy struct {
+	name string
+	fn   func(ctx context.Context, req any) error
+}
+
+func (p policy) Name() string {
+	return p.name
+}
+
+func (p policy) Authorize(ctx context.Context, req any) error {
+	return p.fn(ctx, req)
+}
+
+// RequireRoles 要求拥有任意一个角色
+func RequireRoles(roles ...string) Policy {
+	return policy{name: "require_roles", fn: func(ctx context.Context, _ any) error {
+		if userFromContext(ctx) == "" {
+			return ErrUnauthenticated
+		}
+
+		for _, role := range rolesFromContext(ctx) {
+			if slices.Contains(roles, role) {
+				return nil
+			}
+		}
+
+		return &AuthorizationError{Policy: "require_roles", Reason: "missing role", Required: roles}
+	}}
+}
+
+// RequireScopes 要求拥有全部权限范围
+func RequireScopes(scopes ...string) Policy {
+	return policy{name: "require_scopes", fn: func(ctx context.Context, _ any) error {
+		if userFromContext(ctx) == "" {
+			return ErrUnauthenticated
+		}
+
+		granted := scopesFromContext(ctx)
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				return &AuthorizationError{Policy: "require_scopes", Reason: "missing scope " + scope, Required: scopes}
+			}
+		}
+
+		return nil
+	}}
+}
+
+// Rule 基于属性的策略，fn 可读取声明和请求对象，例如判断资源是否属于当前租户
+func Rule(name string, fn func(ctx context.Context, claims jwt.Claims, req any) bool) Policy {
+	return policy{name: name, fn: func(ctx context.Context, req any) error {
+		var claims jwt.Claims
+		if c, ok := FromContext(ctx); ok {
+			claims = c.Claims()
+		}
+
+		if !fn(ctx, claims, req) {
+			return &AuthorizationError{Policy: name, Reason: "rule denied"}
+		}
+
+		return nil
+	}}
+}
+
+// AnyOf 任意一个策略通过即允许
+func AnyOf(policies ...Policy) Policy {
+	names := make([]string, 0, len(policies))
+	for _, p := range policies {
+		names = append(names, p.Name())
+	}
+
+	name := "any_of(" + strings.Join(names, ",") + ")"
+	return policy{name: name, fn: func(ctx context.Context, req any) error {
+		var err error
+		for _, p := range policies {
+			if err = p.Authorize(ctx, req); err == nil {
+				return nil
+			}
+		}
+
+		if errors.Is(err, ErrUnauthenticated) {
+			return err
+		}
+		return &AuthorizationError{Policy: name, Reason: "no policy satisfied"}
+	}}
+}
+
+// Authorize 路由级授权中间件，可用于 Router.Group 的 Use
+func Authorize(policies ...Policy) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			err := authorize(c, nil, log, policies)
+			if err == nil {
+				next(c)
+				return
+			}
+
+			var authErr *AuthorizationError
+			switch {
+			case errors.As(err, &authErr):
+				_ = c.JSON(http.StatusForbidden, H{"err": ErrForbidden.Error(), "policy": authErr.Policy, "reason": authErr.Reason, "required": authErr.Required})
+			case errors.Is(err, ErrUnauthenticated):
+				_ = c.JSON(http.StatusUnauthorized, H{"err": err.Error()})
+			default:
+				_ = c.JSON(http.StatusForbidden, H{"err": err.Error()})
+			}
+		}
+	}
+}
+
+// ServerAuthorize 服务级授权，与过滤器共用同一条过滤链
+func ServerAuthorize(policies ...Policy) ServerOption {
+	return func(h *handler) {
+		h.filters = append(h.filters, func(ctx context.Context, req any) error {
+			return authorize(ctx, req, h.l, policies)
+		})
+	}
+}
+
+func authorize(ctx context.Context, req any, l *slog.Logger, policies []Policy) error {
+	subject := userFromContext(ctx)
+	for _, p := range policies {
+		if err := p.Authorize(ctx, req); err != nil {
+			l.WarnContext(ctx, "authorization denied", slog.String("policy", p.Name()), slog.String("subject", subject), slog.String("err", err.Error()))
+			return err
+		}
+
+		l.DebugContext(ctx, "authorization allowed", slog.String("policy", p.Name()), slog.String("subject", subject))
+	}
+
+	return nil
+}
+
+func scopesFromContext(ctx context.Context) []string {
+	if c, ok := FromContext(ctx); ok {
+		if claims, ok := c.Claims().(interface{ GetScopes() []string }); ok {
+			return claims.GetScopes()
+		}
+	}
+
+	return nil
+}
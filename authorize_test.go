@@ -0,0 +1,72 @@
+package water
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-water/water/auth"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// withClaims 模拟认证中间件，claims 为 nil 时为匿名请求
+func withClaims(claims *auth.Claims, next HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		if claims != nil {
+			c.SetClaims(claims)
+		}
+		next(c)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	admin := &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "u1"}, Roles: []string{"admin"}, Scopes: []string{"read"}}
+	reader := &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "u2"}, Scopes: []string{"read", "write"}}
+	sameTenant := Rule("same_tenant", func(_ context.Context, claims jwt.Claims, _ any) bool {
+		c, ok := claims.(*auth.Claims)
+		return ok && c.TenantID == "t1"
+	})
+
+	tests := []struct {
+		name     string
+		policies []Policy
+		claims   *auth.Claims
+		want     int
+	}{
+		{"anonymous", []Policy{RequireRoles("admin")}, nil, http.StatusUnauthorized},
+		{"role", []Policy{RequireRoles("admin", "ops")}, admin, http.StatusOK},
+		{"missing role", []Policy{RequireRoles("admin")}, reader, http.StatusForbidden},
+		{"all scopes", []Policy{RequireScopes("read", "write")}, reader, http.StatusOK},
+		{"missing scope", []Policy{RequireScopes("read", "write")}, admin, http.StatusForbidden},
+		{"every policy", []Policy{RequireRoles("admin"), RequireScopes("write")}, admin, http.StatusForbidden},
+		{"any of", []Policy{AnyOf(RequireRoles("admin"), RequireScopes("write"))}, reader, http.StatusOK},
+		{"any of anonymous", []Policy{AnyOf(RequireRoles("admin"), RequireScopes("write"))}, nil, http.StatusUnauthorized},
+		{"rule denied", []Policy{sameTenant}, admin, http.StatusForbidden},
+	}
+
+	w := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := withClaims(tt.claims, Authorize(tt.policies...)(func(c *Context) {}))
+			if rec := serve(w, h, httptest.NewRequest("GET", "/", nil)); rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
+
+func TestAuthorizationError(t *testing.T) {
+	c := newTestContext(New(), "127.0.0.1:1", nil)
+	c.SetClaims(&auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "u1"}})
+
+	err := RequireScopes("write").Authorize(c, nil)
+	var authErr *AuthorizationError
+	if !errors.As(err, &authErr) || !errors.Is(err, ErrForbidden) {
+		t.Fatalf("Authorize() = %v, want *AuthorizationError wrapping ErrForbidden", err)
+	}
+	if authErr.Policy != "require_scopes" || len(authErr.Required) != 1 {
+		t.Fatalf("AuthorizationError = %+v", authErr)
+	}
+}
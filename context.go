@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html/template"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -18,12 +21,24 @@ import (
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/go-water/water/auth"
 	"github.com/go-water/water/binding"
 	"github.com/go-water/water/render"
+	"github.com/go-water/water/sessions"
+	"github.com/go-water/water/tracing"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 const (
-	ContextKey = "_go-water/context-key"
+	ContextKey   = "_go-water/context-key"
+	ClaimsKey    = "_go-water/claims"
+	APIKeyKey    = "_go-water/api-key"
+	SessionKey   = "_go-water/session"
+	CSRFKey      = "_go-water/csrf"
+	CSPNonceKey  = "_go-water/csp-nonce"
+	RequestIDKey = "_go-water/request-id"
+	LoggerKey    = "_go-water/logger"
+	SpanKey      = "_go-water/span"
 )
 
 var MaxMultipartMemory int64 = 32 << 20 // 32 MB
@@ -39,11 +54,22 @@ type Context struct {
 
 	sameSite http.SameSite
 	wt       *Water
+	w        responseWriter
 
 	queryCache url.Values
 	formCache  url.Values
 }
 
+// FromContext 从 context.Context 中取出 water.Context
+func FromContext(ctx context.Context) (*Context, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	c, ok := ctx.Value(ContextKey).(*Context)
+	return c, ok
+}
+
 func (c *Context) reset() {
 	c.sameSite = 0
 	c.Keys = nil
@@ -66,6 +92,15 @@ func (c *Context) Param(key string) string {
 	return c.Request.PathValue(key)
 }
 
+// Route 返回匹配到的路由模式，不含请求方法，如 /users/{id}
+func (c *Context) Route() string {
+	pattern := c.Request.Pattern
+	if i := strings.IndexAny(pattern, " \t"); i >= 0 {
+		pattern = strings.TrimLeft(pattern[i:], " \t")
+	}
+	return pattern
+}
+
 func (c *Context) Query(key string) (value string) {
 	value, _ = c.GetQuery(key)
 	return
@@ -353,6 +388,115 @@ func (c *Context) GetStringMapStringSlice(key string) (smss map[string][]string)
 	return
 }
 
+// SetClaims 保存认证通过后的声明，供 Claims 和用户限流读取
+func (c *Context) SetClaims(claims jwt.Claims) {
+	c.Set(ClaimsKey, claims)
+}
+
+func (c *Context) Claims() (claims jwt.Claims) {
+	if val, ok := c.Get(ClaimsKey); ok && val != nil {
+		claims, _ = val.(jwt.Claims)
+	}
+	return
+}
+
+// APIKey 返回 APIKeyAuth 校验通过的密钥
+func (c *Context) APIKey() (key *auth.APIKey) {
+	if val, ok := c.Get(APIKeyKey); ok && val != nil {
+		key, _ = val.(*auth.APIKey)
+	}
+	return
+}
+
+// Session 返回 Sessions 中间件管理的会话，首次调用时从存储中加载，未使用该中间件时返回 nil
+func (c *Context) Session() *sessions.Session {
+	val, ok := c.Get(SessionKey)
+	if !ok {
+		return nil
+	}
+
+	state, ok := val.(*sessionState)
+	if !ok {
+		return nil
+	}
+
+	return state.load(c)
+}
+
+// CSRFToken 返回当前请求的 CSRF token
+func (c *Context) CSRFToken() string {
+	return c.GetString(CSRFKey)
+}
+
+// CSRFField 返回包含 CSRF token 的隐藏表单字段，模板中使用 {{ csrfField }}（见 TemplateFuncs），
+// 或放入模板数据，如 H{"csrfField": c.CSRFField()}
+func (c *Context) CSRFField() template.HTML {
+	token := c.CSRFToken()
+	if token == "" {
+		return ""
+	}
+
+	return template.HTML(fmt.Sprintf(`<input type="hidden" name="%s" value="%s">`,
+		template.HTMLEscapeString(c.GetString(csrfFieldKey)), template.HTMLEscapeString(token)))
+}
+
+// CSPNonce 返回 SecureHeaders 为当前请求生成的 CSP nonce
+func (c *Context) CSPNonce() string {
+	return c.GetString(CSPNonceKey)
+}
+
+// RequestID 返回 RequestID 中间件保存的请求 ID
+func (c *Context) RequestID() string {
+	return c.GetString(RequestIDKey)
+}
+
+// Logger 返回带 request_id 的请求日志，没有使用 RequestID 中间件时返回默认日志
+func (c *Context) Logger() *slog.Logger {
+	if val, ok := c.Get(LoggerKey); ok && val != nil {
+		if l, ok := val.(*slog.Logger); ok {
+			return l
+		}
+	}
+
+	return log
+}
+
+// Span 返回当前请求的追踪 Span，未配置 Water.Tracer 时返回 nil
+func (c *Context) Span() *tracing.Span {
+	span, _ := c.Get(SpanKey)
+	s, _ := span.(*tracing.Span)
+	return s
+}
+
+// Subject 返回当前认证用户，没有 sub 时使用 jti（旧版 SetAuthToken 签发的 token 只有 jti），
+// 兼容旧版通过 Set("uuid", ...) 写入的用户
+func (c *Context) Subject() string {
+	if claims := c.Claims(); claims != nil {
+		if sub, err := claims.GetSubject(); err == nil && sub != "" {
+			return sub
+		}
+		if id := claimsID(claims); id != "" {
+			return id
+		}
+	}
+
+	return c.GetString("uuid")
+}
+
+func claimsID(claims jwt.Claims) string {
+	switch v := claims.(type) {
+	case *auth.Claims:
+		return v.ID
+	case *jwt.RegisteredClaims:
+		return v.ID
+	case jwt.MapClaims:
+		id, _ := v["jti"].(string)
+		return id
+	}
+
+	return ""
+}
+
 func (c *Context) Redirect(code int, location string) {
 	c.Render(-1, render.Redirect{
 		Code:     code,
@@ -461,6 +605,12 @@ func (c *Context) Cookie(name string) (string, error) {
 
 func (c *Context) HTML(code int, name string, obj any) {
 	instance := c.wt.HTMLRender.Instance(name, obj)
+	if html, ok := instance.(render.HTML); ok {
+		if funcs := c.templateFuncs(); funcs != nil {
+			html.Funcs = funcs
+			instance = html
+		}
+	}
 	c.Render(code, instance)
 }
 
@@ -469,26 +619,31 @@ func (c *Context) Text(code int, format string, values ...any) {
 }
 
 func (c *Context) ClientIP() string {
-	if c.wt.TrustedPlatform != "" {
-		if addr := c.GetHeader(c.wt.TrustedPlatform); addr != "" {
-			return addr
+	remoteIP, err := netip.ParseAddr(c.RemoteIP())
+	if err != nil {
+		return ""
+	}
+
+	// 显式设置了 TrustedPlatform 但没有调用 SetTrustedProxies 时，与旧版一样信任平台头部
+	if c.wt.TrustedPlatform != "" && (c.wt.trustedProxies == nil || c.wt.isTrustedProxy(remoteIP)) {
+		if addr, err := netip.ParseAddr(strings.TrimSpace(c.GetHeader(c.wt.TrustedPlatform))); err == nil {
+			return addr.Unmap().String()
 		}
 	}
 
-	remoteIP := net.ParseIP(c.RemoteIP())
-	if remoteIP == nil {
-		return ""
+	if !c.wt.isTrustedProxy(remoteIP) {
+		return remoteIP.Unmap().String()
 	}
 
 	if c.wt.RemoteIPHeaders != nil {
 		for _, headerName := range c.wt.RemoteIPHeaders {
-			ip, valid := c.wt.validateHeader(c.GetHeader(headerName))
+			ip, valid := c.wt.validateHeader(headerName, strings.Join(c.Request.Header.Values(headerName), ","))
 			if valid {
 				return ip
 			}
 		}
 	}
-	return remoteIP.String()
+	return remoteIP.Unmap().String()
 }
 
 func (c *Context) RemoteIP() string {
@@ -511,6 +666,25 @@ func bodyAllowedForStatus(status int) bool {
 	return true
 }
 
+// ResponseStatus 返回已写出的状态码，未写出时为 200
+func (c *Context) ResponseStatus() int {
+	return c.w.status
+}
+
+// ResponseSize 返回已写出的响应体字节数
+func (c *Context) ResponseSize() int {
+	return c.w.size
+}
+
+// Written 响应头是否已经写出
+func (c *Context) Written() bool {
+	return c.w.written
+}
+
+func (c *Context) beforeWrite(fn func()) {
+	c.w.beforeWrite = append(c.w.beforeWrite, fn)
+}
+
 func (c *Context) Status(code int) {
 	if code > 0 && code != http.StatusOK {
 		c.Writer.WriteHeader(code)
@@ -575,6 +749,12 @@ func Bind[T any](c *Context) (t *T, err error) {
 	return obj.(*T), nil
 }
 
+// ClaimsAs 按指定类型取出 JWTAuth 写入的声明
+func ClaimsAs[T jwt.Claims](c *Context) (t T, ok bool) {
+	t, ok = c.Claims().(T)
+	return
+}
+
 func (c *Context) hasRequestContext() bool {
 	hasFallback := c.wt != nil && c.wt.ContextWithFallback
 	hasRequestContext := c.Request != nil && c.Request.Context() != nil
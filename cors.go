@@ -0,0 +1,189 @@
+package water
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type cors struct {
+	allowAll    bool
+	origins     []string
+	wildcards   [][2]string
+	originFunc  func(origin string) bool
+	methods     []string
+	headers     []string
+	expose      []string
+	credentials bool
+	maxAge      time.Duration
+}
+
+type CORSOption func(c *cors)
+
+// CORSOrigins 允许的来源，支持精确匹配、"*" 和 "https://*.example.com" 形式的通配
+func CORSOrigins(origins ...string) CORSOption {
+	return func(c *cors) {
+		for _, origin := range origins {
+			origin = strings.ToLower(origin)
+			switch {
+			case origin == "*":
+				c.allowAll = true
+			case strings.Contains(origin, "*"):
+				prefix, suffix, _ := strings.Cut(origin, "*")
+				c.wildcards = append(c.wildcards, [2]string{prefix, suffix})
+			default:
+				c.origins = append(c.origins, origin)
+			}
+		}
+	}
+}
+
+// CORSOriginFunc 自定义来源校验，与 CORSOrigins 任一通过即允许
+func CORSOriginFunc(fn func(origin string) bool) CORSOption {
+	return func(c *cors) { c.originFunc = fn }
+}
+
+func CORSMethods(methods ...string) CORSOption {
+	return func(c *cors) { c.methods = methods }
+}
+
+// CORSHeaders 允许的请求头，包含 "*" 时允许预检请求中的所有请求头
+func CORSHeaders(headers ...string) CORSOption {
+	return func(c *cors) { c.headers = headers }
+}
+
+func CORSExposeHeaders(headers ...string) CORSOption {
+	return func(c *cors) { c.expose = headers }
+}
+
+// CORSCredentials 允许携带 Cookie 等凭证，不能与 CORSOrigins("*") 同时使用，
+// 需要动态放行来源时使用 CORSOriginFunc
+func CORSCredentials() CORSOption {
+	return func(c *cors) { c.credentials = true }
+}
+
+func CORSMaxAge(maxAge time.Duration) CORSOption {
+	return func(c *cors) { c.maxAge = maxAge }
+}
+
+// CORS 跨域中间件，通过 Water.UseHttpHandler 安装，
+// 在路由匹配之前执行，没有注册 OPTIONS 路由的路径也能响应预检请求
+func CORS(options ...CORSOption) HttpHandler {
+	c := &cors{
+		methods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead},
+		headers: []string{"Origin", "Accept", "Content-Type", "Authorization", "X-Requested-With"},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	if c.allowAll && c.credentials {
+		panic("water: CORS cannot allow all origins with credentials, list the origins or use CORSOriginFunc")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				header.Add("Vary", "Origin")
+				header.Add("Vary", "Access-Control-Request-Method")
+				header.Add("Vary", "Access-Control-Request-Headers")
+				c.preflight(w, r, origin)
+				return
+			}
+
+			if !c.allowAll {
+				header.Add("Vary", "Origin")
+			}
+			if c.allowOrigin(origin) {
+				c.writeOrigin(header, origin)
+				if len(c.expose) > 0 {
+					header.Set("Access-Control-Expose-Headers", strings.Join(c.expose, ", "))
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (c *cors) preflight(w http.ResponseWriter, r *http.Request, origin string) {
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	requested := parseHeaderList(r.Header.Get("Access-Control-Request-Headers"))
+	if !c.allowOrigin(origin) || !slices.Contains(c.methods, method) || !c.allowHeaders(requested) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	header := w.Header()
+	c.writeOrigin(header, origin)
+	header.Set("Access-Control-Allow-Methods", strings.Join(c.methods, ", "))
+	if len(requested) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+	}
+	if c.maxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(c.maxAge.Seconds())))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *cors) writeOrigin(header http.Header, origin string) {
+	if c.allowAll {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if c.credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (c *cors) allowOrigin(origin string) bool {
+	if c.allowAll {
+		return true
+	}
+
+	lower := strings.ToLower(origin)
+	if slices.Contains(c.origins, lower) {
+		return true
+	}
+	for _, w := range c.wildcards {
+		if len(lower) >= len(w[0])+len(w[1]) && strings.HasPrefix(lower, w[0]) && strings.HasSuffix(lower, w[1]) {
+			return true
+		}
+	}
+
+	return c.originFunc != nil && c.originFunc(origin)
+}
+
+func (c *cors) allowHeaders(requested []string) bool {
+	if slices.Contains(c.headers, "*") {
+		return true
+	}
+
+	for _, h := range requested {
+		if !slices.ContainsFunc(c.headers, func(allowed string) bool { return strings.EqualFold(allowed, h) }) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseHeaderList(value string) []string {
+	var headers []string
+	for _, h := range strings.Split(value, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			headers = append(headers, http.CanonicalHeaderKey(h))
+		}
+	}
+
+	return headers
+}
@@ -0,0 +1,93 @@
+package water
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func corsRequest(h HttpHandler, method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/", nil)
+	req.Header.Set("Origin", origin)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	rec := httptest.NewRecorder()
+	h(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSAllowAllWithCredentialsPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("CORS(\"*\", credentials) did not panic")
+		}
+	}()
+	CORS(CORSOrigins("*"), CORSCredentials())
+}
+
+func TestCORSAllowAll(t *testing.T) {
+	rec := corsRequest(CORS(CORSOrigins("*")), "GET", "https://evil.test", nil)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("Allow-Origin = %q, want *", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Fatalf("Allow-Credentials = %q, want empty", got)
+	}
+}
+
+func TestCORSCredentials(t *testing.T) {
+	h := CORS(
+		CORSOrigins("https://app.example.com", "https://*.example.org"),
+		CORSOriginFunc(func(origin string) bool { return origin == "https://partner.test" }),
+		CORSCredentials(),
+	)
+
+	for _, origin := range []string{"https://app.example.com", "https://a.example.org", "https://partner.test"} {
+		rec := corsRequest(h, "GET", origin, nil)
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != origin {
+			t.Errorf("%s: Allow-Origin = %q", origin, got)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+			t.Errorf("%s: Allow-Credentials = %q", origin, got)
+		}
+	}
+
+	rec := corsRequest(h, "GET", "https://evil.test", nil)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("disallowed origin got Allow-Origin %q", got)
+	}
+	if got := rec.Header().Get("Vary"); got != "Origin" {
+		t.Fatalf("Vary = %q, want Origin", got)
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	h := CORS(CORSOrigins("https://app.example.com"), CORSMaxAge(10*time.Minute))
+
+	rec := corsRequest(h, "OPTIONS", "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method":  "PUT",
+		"Access-Control-Request-Headers": "content-type",
+	})
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Headers"); got != "Content-Type" {
+		t.Fatalf("Allow-Headers = %q", got)
+	}
+	if got := rec.Header().Get("Access-Control-Max-Age"); got != "600" {
+		t.Fatalf("Max-Age = %q", got)
+	}
+
+	rec = corsRequest(h, "OPTIONS", "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method":  "PUT",
+		"Access-Control-Request-Headers": "X-Secret",
+	})
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("preflight with disallowed header got Allow-Origin %q", got)
+	}
+}
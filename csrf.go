@@ -0,0 +1,154 @@
+package water
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const (
+	errCSRFMismatch = Err("csrf token mismatch")
+	errCSRFSession  = Err("csrf: CSRFSession requires the Sessions middleware before CSRF")
+
+	csrfFieldKey = "_go-water/csrf-field"
+)
+
+type csrf struct {
+	cookie  string
+	header  string
+	field   string
+	exempt  []string
+	session bool
+	secure  bool
+	maxAge  int
+}
+
+type CSRFOption func(c *csrf)
+
+// CSRFCookie 双重提交模式下保存 token 的 cookie 名，默认 _csrf
+func CSRFCookie(name string) CSRFOption {
+	return func(c *csrf) { c.cookie = name }
+}
+
+// CSRFHeader AJAX 请求提交 token 的请求头，默认 X-CSRF-Token
+func CSRFHeader(name string) CSRFOption {
+	return func(c *csrf) { c.header = name }
+}
+
+// CSRFField 表单提交 token 的字段名，默认 _csrf
+func CSRFField(name string) CSRFOption {
+	return func(c *csrf) { c.field = name }
+}
+
+// CSRFExempt 不做校验的路径，以 * 结尾时按前缀匹配
+func CSRFExempt(paths ...string) CSRFOption {
+	return func(c *csrf) { c.exempt = append(c.exempt, paths...) }
+}
+
+// CSRFSession 使用同步令牌模式，token 保存在会话中，需要先使用 Sessions 中间件，
+// 否则所有请求返回 500 并记录错误日志
+func CSRFSession() CSRFOption {
+	return func(c *csrf) { c.session = true }
+}
+
+func CSRFSecure(secure bool) CSRFOption {
+	return func(c *csrf) { c.secure = secure }
+}
+
+// CSRF 跨站请求伪造防护，默认使用双重提交 cookie 模式，
+// 模板中通过 {{ csrfField }} 输出隐藏字段，或 {{ csrfToken }} 输出 token 供 AJAX 放入请求头，
+// 模板解析时需要加入 TemplateFuncs
+func CSRF(options ...CSRFOption) Middleware {
+	cf := &csrf{
+		cookie: "_csrf",
+		header: "X-CSRF-Token",
+		field:  "_csrf",
+		maxAge: 12 * 3600,
+	}
+	for _, option := range options {
+		option(cf)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if cf.session && c.Session() == nil {
+				log.Error(errCSRFSession.Error(), slog.String("path", c.Request.URL.Path))
+				_ = c.JSON(http.StatusInternalServerError, H{"err": errCSRFSession.Error()})
+				return
+			}
+
+			token := cf.token(c)
+			if token == "" {
+				token = newCSRFToken()
+				cf.store(c, token)
+			}
+
+			c.Set(CSRFKey, token)
+			c.Set(csrfFieldKey, cf.field)
+
+			if !safeMethod(c.Request.Method) && !cf.isExempt(c.Request.URL.Path) {
+				submitted := c.GetHeader(cf.header)
+				if submitted == "" {
+					submitted = c.PostForm(cf.field)
+				}
+
+				if subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+					_ = c.JSON(http.StatusForbidden, H{"err": errCSRFMismatch.Error()})
+					return
+				}
+			}
+
+			next(c)
+		}
+	}
+}
+
+func (cf *csrf) token(c *Context) string {
+	if cf.session {
+		token, _ := c.Session().Get(CSRFKey).(string)
+		return token
+	}
+
+	token, _ := c.Cookie(cf.cookie)
+	return token
+}
+
+func (cf *csrf) store(c *Context, token string) {
+	if cf.session {
+		c.Session().Set(CSRFKey, token)
+		return
+	}
+
+	c.SetCookie(cf.cookie, token, cf.maxAge, "/", "", cf.secure, true)
+}
+
+func (cf *csrf) isExempt(path string) bool {
+	for _, exempt := range cf.exempt {
+		if prefix, ok := strings.CutSuffix(exempt, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == exempt {
+			return true
+		}
+	}
+
+	return false
+}
+
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func newCSRFToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
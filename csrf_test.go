@@ -0,0 +1,174 @@
+package water
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-water/water/multitemplate"
+	"github.com/go-water/water/sessions"
+)
+
+func csrfCookie(t *testing.T, rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	t.Helper()
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	t.Fatalf("response has no %s cookie", name)
+	return nil
+}
+
+func TestCSRFDoubleSubmit(t *testing.T) {
+	w := New()
+	var field template.HTML
+	h := CSRF(CSRFExempt("/webhooks/*"))(func(c *Context) {
+		field = c.CSRFField()
+		c.Status(http.StatusNoContent)
+	})
+
+	rec := serve(w, h, httptest.NewRequest("GET", "/form", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("GET status = %d", rec.Code)
+	}
+	cookie := csrfCookie(t, rec, "_csrf")
+	if !strings.Contains(string(field), `name="_csrf"`) || !strings.Contains(string(field), cookie.Value) {
+		t.Fatalf("CSRFField() = %q", field)
+	}
+
+	post := func(token string, form bool) int {
+		var req *http.Request
+		if form {
+			req = httptest.NewRequest("POST", "/form", strings.NewReader(url.Values{"_csrf": {token}}.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			req = httptest.NewRequest("POST", "/form", nil)
+			req.Header.Set("X-CSRF-Token", token)
+		}
+		req.AddCookie(cookie)
+		return serve(w, h, req).Code
+	}
+
+	if code := post(cookie.Value, false); code != http.StatusNoContent {
+		t.Errorf("POST with header token status = %d", code)
+	}
+	if code := post(cookie.Value, true); code != http.StatusNoContent {
+		t.Errorf("POST with form token status = %d", code)
+	}
+	if code := post("forged", false); code != http.StatusForbidden {
+		t.Errorf("POST with wrong token status = %d, want 403", code)
+	}
+	if code := post("", false); code != http.StatusForbidden {
+		t.Errorf("POST without token status = %d, want 403", code)
+	}
+
+	// 没有 cookie 时提交任意 token 都会被拒绝
+	req := httptest.NewRequest("POST", "/form", nil)
+	req.Header.Set("X-CSRF-Token", cookie.Value)
+	if code := serve(w, h, req).Code; code != http.StatusForbidden {
+		t.Errorf("POST without cookie status = %d, want 403", code)
+	}
+
+	if code := serve(w, h, httptest.NewRequest("POST", "/webhooks/github", nil)).Code; code != http.StatusNoContent {
+		t.Errorf("exempt POST status = %d", code)
+	}
+}
+
+func TestCSRFSessionRequiresSessions(t *testing.T) {
+	h := CSRF(CSRFSession())(func(c *Context) { c.Status(http.StatusNoContent) })
+	if code := serve(New(), h, httptest.NewRequest("GET", "/", nil)).Code; code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", code)
+	}
+}
+
+func TestCSRFSession(t *testing.T) {
+	store, err := sessions.NewCookieStore(bytes.Repeat([]byte("k"), 32), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := New()
+	var token string
+	h := Sessions("sid", store)(CSRF(CSRFSession())(func(c *Context) {
+		token = c.CSRFToken()
+		c.Status(http.StatusNoContent)
+	}))
+
+	rec := serve(w, h, httptest.NewRequest("GET", "/", nil))
+	session := csrfCookie(t, rec, "sid")
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == "_csrf" {
+			t.Fatal("session mode must not set the double-submit cookie")
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req.AddCookie(session)
+	req.Header.Set("X-CSRF-Token", token)
+	if code := serve(w, h, req).Code; code != http.StatusNoContent {
+		t.Fatalf("POST with session token status = %d", code)
+	}
+
+	req = httptest.NewRequest("POST", "/", nil)
+	req.AddCookie(session)
+	req.Header.Set("X-CSRF-Token", "forged")
+	if code := serve(w, h, req).Code; code != http.StatusForbidden {
+		t.Fatalf("POST with forged token status = %d, want 403", code)
+	}
+}
+
+func TestCSRFFieldInTemplate(t *testing.T) {
+	views := multitemplate.New()
+	views.AddFromString("form", `<form>{{ .csrfField }}</form>`)
+	w := New()
+	w.HTMLRender = views
+
+	h := CSRF()(func(c *Context) {
+		c.HTML(http.StatusOK, "form", H{"csrfField": c.CSRFField()})
+	})
+
+	// 同一模板多次渲染，每次输出当前请求的 token
+	for i := 0; i < 2; i++ {
+		rec := serve(w, h, httptest.NewRequest("GET", "/", nil))
+		cookie := csrfCookie(t, rec, "_csrf")
+		if !strings.Contains(rec.Body.String(), `value="`+cookie.Value+`"`) {
+			t.Fatalf("render %d body = %q", i, rec.Body.String())
+		}
+	}
+}
+
+func TestCSRFTemplateFuncs(t *testing.T) {
+	views := multitemplate.New()
+	views.AddFromStringsFuncs("form", TemplateFuncs(), `<form>{{ csrfField }}<i>{{ csrfToken }}</i></form>`)
+	w := New()
+	w.HTMLRender = views
+
+	plain := func(c *Context) { c.HTML(http.StatusOK, "form", nil) }
+	protected := CSRF()(plain)
+
+	// 没有 CSRF 的路由先执行同一个模板，之后仍然可以绑定请求级函数
+	if rec := serve(w, plain, httptest.NewRequest("GET", "/", nil)); rec.Body.String() != "<form><i></i></form>" {
+		t.Fatalf("render without CSRF = %q", rec.Body.String())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := serve(w, protected, httptest.NewRequest("GET", "/", nil))
+			cookie := csrfCookie(t, rec, "_csrf")
+			want := `<input type="hidden" name="_csrf" value="` + cookie.Value + `"><i>` + cookie.Value + `</i>`
+			if !strings.Contains(rec.Body.String(), want) {
+				t.Errorf("body = %q, want %q", rec.Body.String(), want)
+			}
+		}()
+	}
+	wg.Wait()
+}
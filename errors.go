@@ -1,5 +1,10 @@
 package water
 
+const (
+	ErrUnauthenticated = Err("user not authenticated")
+	ErrForbidden       = Err("permission denied")
+)
+
 type Err string
 
 func (e Err) Error() string {
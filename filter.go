@@ -0,0 +1,71 @@
+package water
+
+import (
+	"context"
+	"fmt"
+)
+
+type Filter func(ctx context.Context) error
+
+type FilterFunc func() Filter
+
+// RequestFilter 可读取请求对象的过滤器，用于所有权校验等业务前置校验
+type RequestFilter func(ctx context.Context, req any) error
+
+func (f Filter) request() RequestFilter {
+	return func(ctx context.Context, _ any) error {
+		return f(ctx)
+	}
+}
+
+// TypedFilter 把强类型校验函数转换为 RequestFilter，请求类型不匹配时返回错误
+func TypedFilter[T any](fn func(ctx context.Context, req *T) error) RequestFilter {
+	return func(ctx context.Context, req any) error {
+		r, ok := req.(*T)
+		if !ok {
+			return fmt.Errorf("filter expects request of type %T, got %T", r, req)
+		}
+
+		return fn(ctx, r)
+	}
+}
+
+// AuthenticatedFilter 要求请求已登录
+func AuthenticatedFilter() Filter {
+	return func(ctx context.Context) error {
+		if userFromContext(ctx) == "" {
+			return ErrUnauthenticated
+		}
+
+		return nil
+	}
+}
+
+// RoleFilter 要求请求用户拥有任意一个指定角色，同 RequireRoles
+func RoleFilter(roles ...string) Filter {
+	policy := RequireRoles(roles...)
+	return func(ctx context.Context) error {
+		return policy.Authorize(ctx, nil)
+	}
+}
+
+func userFromContext(ctx context.Context) string {
+	if c, ok := FromContext(ctx); ok {
+		return c.Subject()
+	}
+
+	return ""
+}
+
+func rolesFromContext(ctx context.Context) []string {
+	c, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	if claims, ok := c.Claims().(interface{ GetRoles() []string }); ok {
+		return claims.GetRoles()
+	}
+
+	return c.GetStringSlice("roles")
+}
@@ -6,10 +6,13 @@ import (
 	"log/slog"
 	"net/http"
 	"reflect"
+	"time"
 
 	"github.com/go-water/water/circuitbreaker"
 	"github.com/go-water/water/endpoint"
+	"github.com/go-water/water/health"
 	"github.com/go-water/water/logger"
+	"github.com/go-water/water/metrics"
 	"github.com/go-water/water/ratelimit"
 	"github.com/sony/gobreaker"
 	"golang.org/x/time/rate"
@@ -17,6 +20,18 @@ import (
 
 type HandlerFunc func(*Context)
 
+// WrapH 把 http.Handler 转换为 HandlerFunc
+func WrapH(h http.Handler) HandlerFunc {
+	return func(c *Context) {
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// WrapF 把 http.HandlerFunc 转换为 HandlerFunc
+func WrapF(f http.HandlerFunc) HandlerFunc {
+	return WrapH(f)
+}
+
 type RouterHandler struct {
 	wt *Water
 	h  HandlerFunc
@@ -24,24 +39,35 @@ type RouterHandler struct {
 
 func (r *RouterHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	ctx := r.wt.pool.Get().(*Context)
-	ctx.Writer = w
+	ctx.w.reset(w)
+	ctx.Writer = &ctx.w
 	ctx.Request = req
 	ctx.wt = r.wt
 	ctx.reset()
 
-	r.h(ctx)
+	if r.wt.Tracer != nil {
+		r.traced(ctx)
+	} else {
+		r.h(ctx)
+	}
 	r.wt.pool.Put(ctx)
 }
 
 type handler struct {
 	e         endpoint.Endpoint
-	filter    Filter
-	finalizer []FinalizerFunc
+	before    []BeforeFunc
+	filters   []RequestFilter
+	after     []AfterFunc
+	finalizer []RequestFinalizerFunc
 	l         *slog.Logger
 	dl        *rate.Limiter
 	el        *rate.Limiter
 	eus       *ratelimit.UserBasedLimiter
 	breaker   *gobreaker.CircuitBreaker
+	name      string
+	registry  *metrics.Registry
+	health    *health.Registry
+	metrics   *serviceMetrics
 }
 
 func NewHandler(srv Service, options ...ServerOption) Handler {
@@ -61,18 +87,18 @@ func NewHandler(srv Service, options ...ServerOption) Handler {
 		h.e = circuitbreaker.GoBreaker(h.breaker)(h.e)
 	}
 	if h.eus != nil {
-		getUser := func(ctx context.Context) string {
-			// 从Context中提取water.Context
-			val := ctx.Value("_go-water/context-key")
-			if waterCtx, ok := val.(*Context); ok {
-				return waterCtx.GetString("uuid")
-			}
-			return ""
-		}
-		h.e = h.eus.UserErrorLimiter(getUser)(h.e)
+		h.e = h.eus.UserErrorLimiter(userFromContext)(h.e)
+	}
+
+	h.name = srv.Name(srv)
+	if h.registry != nil {
+		h.metrics = newServiceMetrics(h.registry, h.name, h.breaker)
+	}
+	if h.health != nil && h.breaker != nil {
+		h.health.Breaker(h.name, h.breaker)
 	}
 
-	l := logger.NewLogger(logger.Level, logger.AddSource).With(slog.String("name", srv.Name(srv)))
+	l := logger.NewServiceLogger(h.name)
 	srv.SetLogger(l)
 	h.l = l
 
@@ -128,27 +154,53 @@ func (h *handler) readRequest(ctx context.Context, service Service, req any) (fu
 }
 
 func (h *handler) ServerWater(ctx context.Context, req any) (resp any, err error) {
+	var rejected bool
+	if h.metrics != nil {
+		begin := time.Now()
+		defer func() { h.metrics.observe(err, rejected, time.Since(begin)) }()
+	}
+
+	if parent := spanFromContext(ctx); parent != nil {
+		var span *tracedSpan
+		ctx, span = h.startSpan(ctx, parent)
+		defer func() { h.endSpan(span, err, rejected) }()
+	}
+
 	if len(h.finalizer) > 0 {
+		begin := time.Now()
 		defer func() {
+			duration := time.Since(begin)
 			for _, fn := range h.finalizer {
-				fn(ctx, err)
+				fn(ctx, req, resp, err, duration)
 			}
 		}()
 	}
 
-	if h.filter != nil {
-		err = h.filter(ctx)
+	for _, before := range h.before {
+		ctx = before(ctx, req)
+	}
+
+	for _, filter := range h.filters {
+		err = filter(ctx, req)
 		if err != nil {
+			rejected = true
 			return nil, err
 		}
 	}
 
 	resp, err = h.e(ctx, req)
 	if err != nil {
-		h.l.Error(err.Error())
+		withRequestID(ctx, h.l).Error(err.Error())
 		return nil, err
 	}
 
+	for _, after := range h.after {
+		resp, err = after(ctx, req, resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return resp, nil
 }
 
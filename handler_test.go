@@ -0,0 +1,107 @@
+package water
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type echoRequest struct {
+	Owner string
+}
+
+type echoService struct{ ServerBase }
+
+func (s *echoService) Handle(ctx context.Context, req *echoRequest) (string, error) {
+	if tag, ok := ctx.Value(echoTag{}).(string); ok {
+		return req.Owner + ":" + tag, nil
+	}
+	return req.Owner, nil
+}
+
+type echoTag struct{}
+
+func TestServerFiltersOrder(t *testing.T) {
+	var calls []string
+	record := func(name string, err error) Filter {
+		return func(ctx context.Context) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+	denied := errors.New("denied")
+
+	h := NewHandler(&echoService{}, ServerFilters(record("first", nil), record("second", denied), record("third", nil)))
+	if _, err := h.ServerWater(context.Background(), &echoRequest{}); !errors.Is(err, denied) {
+		t.Fatalf("ServerWater() = %v, want denied", err)
+	}
+	if !slices.Equal(calls, []string{"first", "second"}) {
+		t.Fatalf("filters called = %v, want to stop at the first error", calls)
+	}
+}
+
+func TestServerRequestFilters(t *testing.T) {
+	owner := TypedFilter(func(ctx context.Context, req *echoRequest) error {
+		if req.Owner != "u1" {
+			return ErrForbidden
+		}
+		return nil
+	})
+	h := NewHandler(&echoService{}, ServerRequestFilters(owner))
+
+	if resp, err := h.ServerWater(context.Background(), &echoRequest{Owner: "u1"}); err != nil || resp != "u1" {
+		t.Fatalf("ServerWater() = %v, %v", resp, err)
+	}
+	if _, err := h.ServerWater(context.Background(), &echoRequest{Owner: "u2"}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("other owner: %v, want ErrForbidden", err)
+	}
+	if _, err := h.ServerWater(context.Background(), &tracedRequest{}); err == nil || !strings.Contains(err.Error(), "filter expects request") {
+		t.Fatalf("wrong request type: %v", err)
+	}
+}
+
+func TestServerHooks(t *testing.T) {
+	var finalized error
+	var duration time.Duration
+	h := NewHandler(&echoService{},
+		ServerBefore(func(ctx context.Context, req any) context.Context {
+			return context.WithValue(ctx, echoTag{}, "tagged")
+		}),
+		ServerAfter(func(ctx context.Context, req, resp any) (any, error) {
+			return H{"data": resp}, nil
+		}),
+		ServerRequestFinalizer(func(ctx context.Context, req, resp any, err error, d time.Duration) {
+			finalized, duration = err, d
+		}),
+	)
+
+	resp, err := h.ServerWater(context.Background(), &echoRequest{Owner: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := resp.(H)["data"]; data != "u1:tagged" {
+		t.Fatalf("response = %v, want before ctx and after wrapping", resp)
+	}
+	if finalized != nil || duration <= 0 {
+		t.Fatalf("finalizer saw err %v, duration %v", finalized, duration)
+	}
+}
+
+func TestServerAfterError(t *testing.T) {
+	failed := errors.New("transform failed")
+	var finalized error
+	h := NewHandler(&echoService{},
+		ServerAfter(func(ctx context.Context, req, resp any) (any, error) { return nil, failed }),
+		ServerFinalizer(func(ctx context.Context, err error) { finalized = err }),
+	)
+
+	if _, err := h.ServerWater(context.Background(), &echoRequest{}); !errors.Is(err, failed) {
+		t.Fatalf("ServerWater() = %v, want the after error", err)
+	}
+	if !errors.Is(finalized, failed) {
+		t.Fatalf("finalizer saw %v", finalized)
+	}
+}
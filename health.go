@@ -0,0 +1,41 @@
+package water
+
+import (
+	"net/http"
+
+	"github.com/go-water/water/health"
+)
+
+// Health 注册 /livez、/healthz 和 /readyz 探针，reg 为空时创建新的注册表。
+// 探针不经过 Use 注册的中间件，JWTAuth 等鉴权中间件不会拦截探针；
+// 就绪检查只在调用 Water.Shutdown（或 reg.Shutdown）后、关键检查失败或熔断器打开时失败，
+// 直接关闭 http.Server 不会改变就绪状态
+func (w *Water) Health(reg *health.Registry) *health.Registry {
+	if reg == nil {
+		reg = health.NewRegistry()
+	}
+
+	w.health = reg
+	w.probeRoute(health.LivePath, reg.LiveHandler())
+	w.probeRoute(health.HealthPath, reg.HealthHandler())
+	w.probeRoute(health.ReadyPath, reg.ReadyHandler())
+	return reg
+}
+
+// probeRoute 直接注册到路由表，不包装中间件
+func (w *Water) probeRoute(pattern string, h http.Handler) {
+	w.base.add(&registration{
+		RouteInfo: RouteInfo{
+			Method:  http.MethodGet,
+			Pattern: pattern,
+			Handler: nameOfFunction(h),
+			Source:  callerSource(),
+		},
+		handler: WrapH(h),
+	})
+}
+
+// ServerHealth 服务配置了熔断器时，熔断器打开期间就绪检查失败
+func ServerHealth(reg *health.Registry) ServerOption {
+	return func(h *handler) { h.health = reg }
+}
@@ -0,0 +1,42 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// LiveHandler 进程存活即返回 200，不执行检查
+func (r *Registry) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeReport(w, Report{Status: StatusOK})
+	})
+}
+
+// HealthHandler 执行全部检查，有关键检查失败时返回 503
+func (r *Registry) HealthHandler() http.Handler {
+	return r.handler(r.Health)
+}
+
+// ReadyHandler 就绪检查，关闭中或熔断器打开时也返回 503
+func (r *Registry) ReadyHandler() http.Handler {
+	return r.handler(r.Ready)
+}
+
+func (r *Registry) handler(fn func(ctx context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeReport(w, fn(req.Context()))
+	})
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+
+	code := http.StatusOK
+	if !report.Healthy() {
+		code = http.StatusServiceUnavailable
+	}
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,326 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// 默认的探针路径
+const (
+	LivePath   = "/livez"
+	HealthPath = "/healthz"
+	ReadyPath  = "/readyz"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFail     = "fail"
+)
+
+var (
+	ErrShuttingDown = errors.New("server is shutting down")
+	ErrBreakerOpen  = errors.New("circuit breaker is open")
+)
+
+// Check 检查依赖是否可用，返回错误即为失败
+type Check func(ctx context.Context) error
+
+type check struct {
+	name     string
+	fn       Check
+	timeout  time.Duration
+	ttl      time.Duration
+	critical bool
+
+	mu     sync.Mutex
+	result Result
+	flight *flight
+}
+
+// flight 正在执行的检查，done 关闭后 result 可读
+type flight struct {
+	done   chan struct{}
+	result Result
+}
+
+type CheckOption func(c *check)
+
+// Timeout 单次检查的超时时间，默认使用注册表的设置
+func Timeout(d time.Duration) CheckOption {
+	return func(c *check) { c.timeout = d }
+}
+
+// CacheTTL 检查结果的缓存时间，默认使用注册表的设置
+func CacheTTL(d time.Duration) CheckOption {
+	return func(c *check) { c.ttl = d }
+}
+
+// NonCritical 非关键检查失败时整体状态为 degraded，仍然返回 200
+func NonCritical() CheckOption {
+	return func(c *check) { c.critical = false }
+}
+
+type Result struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Critical  bool      `json:"critical"`
+	Duration  string    `json:"duration,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks,omitempty"`
+}
+
+// Healthy 没有关键检查失败
+func (r Report) Healthy() bool {
+	return r.Status != StatusFail
+}
+
+type Registry struct {
+	timeout    time.Duration
+	ttl        time.Duration
+	drainDelay time.Duration
+
+	mu       sync.RWMutex
+	checks   []*check
+	breakers map[string]*gobreaker.CircuitBreaker
+
+	shuttingDown atomic.Bool
+}
+
+type Option func(r *Registry)
+
+// DefaultTimeout 检查的默认超时时间，默认 2 秒
+func DefaultTimeout(d time.Duration) Option {
+	return func(r *Registry) { r.timeout = d }
+}
+
+// DefaultCacheTTL 检查结果的默认缓存时间，默认 1 秒，避免探针频繁访问依赖
+func DefaultCacheTTL(d time.Duration) Option {
+	return func(r *Registry) { r.ttl = d }
+}
+
+// DrainDelay 开始关闭后等待的时间，让负载均衡感知到就绪失败后再停止接收请求
+func DrainDelay(d time.Duration) Option {
+	return func(r *Registry) { r.drainDelay = d }
+}
+
+func NewRegistry(options ...Option) *Registry {
+	r := &Registry{
+		timeout:  2 * time.Second,
+		ttl:      time.Second,
+		breakers: make(map[string]*gobreaker.CircuitBreaker),
+	}
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
+}
+
+// Register 注册检查，默认为关键检查，同名检查会被替换
+func (r *Registry) Register(name string, fn Check, options ...CheckOption) {
+	c := &check{
+		name:     name,
+		fn:       fn,
+		timeout:  r.timeout,
+		ttl:      r.ttl,
+		critical: true,
+	}
+	for _, option := range options {
+		option(c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, exist := range r.checks {
+		if exist.name == name {
+			r.checks[i] = c
+			return
+		}
+	}
+	r.checks = append(r.checks, c)
+}
+
+// Breaker 熔断器打开时就绪检查失败
+func (r *Registry) Breaker(name string, cb *gobreaker.CircuitBreaker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.breakers[name] = cb
+}
+
+// Shutdown 标记开始关闭，之后就绪检查始终失败
+func (r *Registry) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+
+func (r *Registry) ShuttingDown() bool {
+	return r.shuttingDown.Load()
+}
+
+func (r *Registry) DrainDelay() time.Duration {
+	return r.drainDelay
+}
+
+// Health 并发执行所有检查
+func (r *Registry) Health(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]*check(nil), r.checks...)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	return newReport(results)
+}
+
+// Ready 在 Health 的基础上，关闭中或熔断器打开时失败
+func (r *Registry) Ready(ctx context.Context) Report {
+	report := r.Health(ctx)
+
+	var results []Result
+	if r.ShuttingDown() {
+		results = append(results, failed("shutdown", ErrShuttingDown))
+	}
+
+	r.mu.RLock()
+	for _, name := range slices.Sorted(maps.Keys(r.breakers)) {
+		if r.breakers[name].State() == gobreaker.StateOpen {
+			results = append(results, failed("breaker:"+name, ErrBreakerOpen))
+		}
+	}
+	r.mu.RUnlock()
+
+	if len(results) == 0 {
+		return report
+	}
+
+	return newReport(append(report.Checks, results...))
+}
+
+// run 在缓存过期后执行检查，并发的调用方共享同一次执行，检查在锁外运行
+func (c *check) run(ctx context.Context) Result {
+	c.mu.Lock()
+	if !c.result.CheckedAt.IsZero() && time.Since(c.result.CheckedAt) < c.ttl {
+		defer c.mu.Unlock()
+		return c.result
+	}
+	f := c.flight
+	if f == nil {
+		f = &flight{done: make(chan struct{})}
+		c.flight = f
+		// 共享的执行不受单个调用方取消的影响，只受检查自身的超时限制
+		go c.execute(context.WithoutCancel(ctx), f)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.result
+	case <-ctx.Done():
+		// 调用方取消不代表依赖不可用，结果不缓存
+		return c.newResult(time.Now(), ctx.Err())
+	}
+}
+
+func (c *check) execute(ctx context.Context, f *flight) {
+	begin := time.Now()
+	err := c.call(ctx)
+	f.result = c.newResult(begin, err)
+
+	c.mu.Lock()
+	if !errors.Is(err, context.Canceled) {
+		c.result = f.result
+	}
+	c.flight = nil
+	c.mu.Unlock()
+	close(f.done)
+}
+
+func (c *check) newResult(begin time.Time, err error) Result {
+	result := Result{
+		Name:      c.name,
+		Status:    StatusOK,
+		Critical:  c.critical,
+		Duration:  time.Since(begin).String(),
+		CheckedAt: begin,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// call 在超时后直接返回，不依赖检查函数自己处理 ctx
+func (c *check) call(ctx context.Context) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- c.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func failed(name string, err error) Result {
+	return Result{
+		Name:      name,
+		Status:    StatusFail,
+		Error:     err.Error(),
+		Critical:  true,
+		CheckedAt: time.Now(),
+	}
+}
+
+func newReport(results []Result) Report {
+	report := Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusFail {
+			continue
+		}
+		if result.Critical {
+			report.Status = StatusFail
+			break
+		}
+		report.Status = StatusDegraded
+	}
+
+	return report
+}
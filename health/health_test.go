@@ -0,0 +1,220 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+func TestCheckTimeout(t *testing.T) {
+	reg := NewRegistry()
+	// 检查函数不理会 ctx 时也按超时返回
+	reg.Register("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}, Timeout(20*time.Millisecond))
+
+	begin := time.Now()
+	report := reg.Health(context.Background())
+	if elapsed := time.Since(begin); elapsed > 500*time.Millisecond {
+		t.Fatalf("Health() took %v, want the check timeout", elapsed)
+	}
+	if report.Status != StatusFail || report.Checks[0].Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("report = %+v", report)
+	}
+}
+
+func TestCheckPanic(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("panic", func(ctx context.Context) error { panic("boom") })
+
+	if report := reg.Health(context.Background()); report.Status != StatusFail || report.Checks[0].Error != "panic: boom" {
+		t.Fatalf("report = %+v", report)
+	}
+}
+
+func TestCheckCacheTTL(t *testing.T) {
+	var calls atomic.Int32
+	reg := NewRegistry()
+	reg.Register("db", func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}, CacheTTL(50*time.Millisecond))
+
+	for i := 0; i < 3; i++ {
+		reg.Health(context.Background())
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("check ran %d times within TTL, want 1", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	reg.Health(context.Background())
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("check ran %d times after TTL, want 2", n)
+	}
+}
+
+func TestCheckSingleFlight(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	reg := NewRegistry()
+	reg.Register("db", func(ctx context.Context) error {
+		calls.Add(1)
+		<-release
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	reports := make([]Report, 8)
+	for i := range reports {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reports[i] = reg.Health(context.Background())
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("concurrent probes ran the check %d times, want 1", n)
+	}
+	for _, report := range reports {
+		if report.Status != StatusOK {
+			t.Fatalf("report = %+v", report)
+		}
+	}
+}
+
+func TestCheckCallerCancelNotCached(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	reg := NewRegistry()
+	reg.Register("db", func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			<-release
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	report := reg.Health(ctx)
+	if report.Status != StatusFail || report.Checks[0].Error != context.Canceled.Error() {
+		t.Fatalf("canceled report = %+v", report)
+	}
+
+	// 取消的调用方不影响其他探针，共享的执行完成后缓存成功的结果
+	close(release)
+	if report = reg.Health(context.Background()); report.Status != StatusOK {
+		t.Fatalf("report after cancel = %+v", report)
+	}
+	if report = reg.Health(context.Background()); report.Status != StatusOK || calls.Load() != 1 {
+		t.Fatalf("report = %+v, check ran %d times", report, calls.Load())
+	}
+}
+
+func TestCheckContextCanceledNotCached(t *testing.T) {
+	var calls atomic.Int32
+	reg := NewRegistry()
+	reg.Register("db", func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			return context.Canceled
+		}
+		return nil
+	})
+
+	if report := reg.Health(context.Background()); report.Status != StatusFail {
+		t.Fatalf("report = %+v", report)
+	}
+	if report := reg.Health(context.Background()); report.Status != StatusOK || calls.Load() != 2 {
+		t.Fatalf("report = %+v, check ran %d times", report, calls.Load())
+	}
+}
+
+func TestCriticality(t *testing.T) {
+	fail := func(ctx context.Context) error { return errors.New("down") }
+	ok := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		name    string
+		setup   func(r *Registry)
+		status  string
+		healthy bool
+	}{
+		{"all ok", func(r *Registry) { r.Register("db", ok) }, StatusOK, true},
+		{"non-critical fails", func(r *Registry) {
+			r.Register("db", ok)
+			r.Register("cache", fail, NonCritical())
+		}, StatusDegraded, true},
+		{"critical fails", func(r *Registry) {
+			r.Register("cache", fail, NonCritical())
+			r.Register("db", fail)
+		}, StatusFail, false},
+		{"replaced by name", func(r *Registry) {
+			r.Register("db", fail)
+			r.Register("db", ok)
+		}, StatusOK, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := NewRegistry()
+			tt.setup(reg)
+			report := reg.Health(context.Background())
+			if report.Status != tt.status || report.Healthy() != tt.healthy {
+				t.Fatalf("report = %+v, want %s", report, tt.status)
+			}
+		})
+	}
+}
+
+func TestReadyShutdown(t *testing.T) {
+	reg := NewRegistry(DrainDelay(time.Second))
+	reg.Register("db", func(ctx context.Context) error { return nil })
+
+	if report := reg.Ready(context.Background()); report.Status != StatusOK {
+		t.Fatalf("ready before Shutdown = %+v", report)
+	}
+
+	reg.Shutdown()
+	if !reg.ShuttingDown() || reg.DrainDelay() != time.Second {
+		t.Fatalf("ShuttingDown() = %v, DrainDelay() = %v", reg.ShuttingDown(), reg.DrainDelay())
+	}
+	// 关闭后就绪检查失败，存活检查不受影响
+	report := reg.Ready(context.Background())
+	if report.Healthy() || len(report.Checks) != 2 || report.Checks[1].Name != "shutdown" {
+		t.Fatalf("ready after Shutdown = %+v", report)
+	}
+	if report = reg.Health(context.Background()); report.Status != StatusOK {
+		t.Fatalf("health after Shutdown = %+v", report)
+	}
+}
+
+func TestReadyBreaker(t *testing.T) {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "users",
+		Timeout:     time.Hour,
+		ReadyToTrip: func(counts gobreaker.Counts) bool { return counts.ConsecutiveFailures >= 1 },
+	})
+	reg := NewRegistry()
+	reg.Breaker("users", cb)
+
+	if report := reg.Ready(context.Background()); report.Status != StatusOK {
+		t.Fatalf("ready with closed breaker = %+v", report)
+	}
+	_, _ = cb.Execute(func() (any, error) { return nil, errors.New("boom") })
+	report := reg.Ready(context.Background())
+	if report.Healthy() || report.Checks[0].Name != "breaker:users" {
+		t.Fatalf("ready with open breaker = %+v", report)
+	}
+}
@@ -0,0 +1,64 @@
+package water
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-water/water/health"
+)
+
+// routeHandler 返回已注册路由的处理函数
+func routeHandler(t *testing.T, w *Water, method, pattern string) HandlerFunc {
+	t.Helper()
+	for _, rt := range w.base.routes {
+		if rt.Method == method && rt.Pattern == pattern {
+			return rt.handler
+		}
+	}
+
+	t.Fatalf("route %s %s is not registered", method, pattern)
+	return nil
+}
+
+func TestHealthProbesSkipMiddlewares(t *testing.T) {
+	w := New()
+	w.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			_ = c.JSON(http.StatusUnauthorized, H{"err": ErrUnauthenticated.Error()})
+		}
+	})
+	w.Health(nil)
+
+	for _, path := range []string{health.LivePath, health.HealthPath, health.ReadyPath} {
+		rec := serve(w, routeHandler(t, w, http.MethodGet, path), httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s status = %d, want 200", path, rec.Code)
+		}
+	}
+}
+
+func TestHealthReadyFailsAfterShutdown(t *testing.T) {
+	w := New()
+	reg := w.Health(nil)
+	reg.Register("db", func(context.Context) error { return nil })
+	reg.Register("cache", func(context.Context) error { return errors.New("down") }, health.NonCritical())
+
+	ready := routeHandler(t, w, http.MethodGet, health.ReadyPath)
+	live := routeHandler(t, w, http.MethodGet, health.LivePath)
+	if rec := serve(w, ready, httptest.NewRequest(http.MethodGet, health.ReadyPath, nil)); rec.Code != http.StatusOK {
+		t.Fatalf("ready status = %d, want 200 with a degraded non-critical check", rec.Code)
+	}
+
+	if err := w.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if rec := serve(w, ready, httptest.NewRequest(http.MethodGet, health.ReadyPath, nil)); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("ready status after Shutdown = %d, want 503", rec.Code)
+	}
+	if rec := serve(w, live, httptest.NewRequest(http.MethodGet, health.LivePath, nil)); rec.Code != http.StatusOK {
+		t.Fatalf("live status after Shutdown = %d, want 200", rec.Code)
+	}
+}
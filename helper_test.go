@@ -0,0 +1,26 @@
+package water
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+func newTestContext(w *Water, remoteAddr string, headers map[string]string) *Context {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	c := w.allocateContext()
+	c.Request = req
+	c.reset()
+	return c
+}
+
+// serve 以路由的方式执行 h，返回响应
+func serve(w *Water, h HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	(&RouterHandler{wt: w, h: h}).ServeHTTP(rec, req)
+	return rec
+}
@@ -0,0 +1,96 @@
+package water
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-water/water/auth"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type jwtAuth struct {
+	verifier  *auth.JWTVerifier
+	extractor auth.MultiExtractor
+	claims    func() jwt.Claims
+	optional  bool
+	realm     string
+}
+
+type JWTAuthOption func(a *jwtAuth)
+
+// JWTAuthOptional 没有携带 token 时允许匿名访问，携带了无效 token 仍然返回 401
+func JWTAuthOptional() JWTAuthOption {
+	return func(a *jwtAuth) { a.optional = true }
+}
+
+// JWTAuthClaims 指定声明类型，默认为 *auth.Claims
+func JWTAuthClaims(fn func() jwt.Claims) JWTAuthOption {
+	return func(a *jwtAuth) { a.claims = fn }
+}
+
+// JWTAuthExtractor 指定 token 的提取顺序，默认为 auth.DefaultExtractor
+func JWTAuthExtractor(extractors ...auth.Extractor) JWTAuthOption {
+	return func(a *jwtAuth) { a.extractor = extractors }
+}
+
+func JWTAuthRealm(realm string) JWTAuthOption {
+	return func(a *jwtAuth) { a.realm = realm }
+}
+
+// JWTAuth 校验请求中的 token，并把声明保存到 Context
+func JWTAuth(verifier *auth.JWTVerifier, options ...JWTAuthOption) Middleware {
+	a := &jwtAuth{
+		verifier:  verifier,
+		extractor: auth.DefaultExtractor,
+		claims:    func() jwt.Claims { return new(auth.Claims) },
+		realm:     "water",
+	}
+	for _, option := range options {
+		option(a)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			tokenString, extractor, err := a.extractor.Extract(c.Request)
+			if err != nil {
+				if a.optional {
+					next(c)
+					return
+				}
+
+				a.unauthorized(c, nil)
+				return
+			}
+
+			claims := a.claims()
+			if _, err = a.verifier.VerifyContext(c.Request.Context(), tokenString, claims); err != nil {
+				a.unauthorized(c, err)
+				return
+			}
+
+			if selector, ok := extractor.(auth.SubprotocolSelector); ok {
+				if protocol := selector.SelectedSubprotocol(c.Request); protocol != "" {
+					c.Header("Sec-WebSocket-Protocol", protocol)
+				}
+			}
+
+			c.SetClaims(claims)
+			next(c)
+		}
+	}
+}
+
+func (a *jwtAuth) unauthorized(c *Context, err error) {
+	challenge := fmt.Sprintf("Bearer realm=%q", a.realm)
+	if err != nil {
+		description := "invalid token"
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			description = "token expired"
+		}
+		challenge += fmt.Sprintf(", error=\"invalid_token\", error_description=%q", description)
+	}
+
+	c.Header("WWW-Authenticate", challenge)
+	_ = c.JSON(http.StatusUnauthorized, H{"err": ErrUnauthenticated.Error()})
+}
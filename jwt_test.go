@@ -0,0 +1,167 @@
+package water
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-water/water/auth"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var jwtSecret = []byte("secret")
+
+func testJWT(t *testing.T, claims jwt.Claims) string {
+	t.Helper()
+	signer, err := auth.NewJWTSigner(auth.HS256, jwtSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := signer.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func jwtRequest(header, value string) *http.Request {
+	r := httptest.NewRequest("GET", "/", nil)
+	if header != "" {
+		r.Header.Set(header, value)
+	}
+	return r
+}
+
+func TestJWTAuth(t *testing.T) {
+	w := New()
+	verifier := auth.NewJWTVerifier(auth.StaticKey(jwtSecret))
+
+	var subject string
+	var roles []string
+	h := JWTAuth(verifier)(func(c *Context) {
+		subject = c.Subject()
+		if claims, ok := c.Claims().(*auth.Claims); ok {
+			roles = claims.Roles
+		}
+	})
+
+	token := testJWT(t, &auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		Roles:            []string{"admin"},
+	})
+	if rec := serve(w, h, jwtRequest("Authorization", "Bearer "+token)); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if subject != "user-1" || len(roles) != 1 || roles[0] != "admin" {
+		t.Fatalf("claims on Context: subject %q, roles %v", subject, roles)
+	}
+}
+
+func TestJWTAuthUnauthorized(t *testing.T) {
+	w := New()
+	h := JWTAuth(auth.NewJWTVerifier(auth.StaticKey(jwtSecret)), JWTAuthRealm("api"))(func(c *Context) {
+		t.Error("handler called without a valid token")
+	})
+
+	expired := testJWT(t, jwt.RegisteredClaims{Subject: "user-1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))})
+	tests := []struct {
+		name      string
+		req       *http.Request
+		challenge string
+	}{
+		{"missing", jwtRequest("", ""), `Bearer realm="api"`},
+		{"garbage", jwtRequest("Authorization", "Bearer a.b.c"), `error_description="invalid token"`},
+		{"expired", jwtRequest("Authorization", "Bearer "+expired), `error_description="token expired"`},
+	}
+	for _, tt := range tests {
+		rec := serve(w, h, tt.req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", tt.name, rec.Code)
+		}
+		if got := rec.Header().Get("WWW-Authenticate"); !strings.Contains(got, tt.challenge) {
+			t.Errorf("%s: WWW-Authenticate = %q, want %q", tt.name, got, tt.challenge)
+		}
+	}
+}
+
+func TestJWTAuthOptional(t *testing.T) {
+	w := New()
+	called := 0
+	h := JWTAuth(auth.NewJWTVerifier(auth.StaticKey(jwtSecret)), JWTAuthOptional())(func(c *Context) {
+		called++
+		if c.Claims() != nil {
+			t.Error("anonymous request has claims")
+		}
+	})
+
+	if rec := serve(w, h, jwtRequest("", "")); rec.Code != http.StatusOK || called != 1 {
+		t.Fatalf("anonymous status = %d, called %d", rec.Code, called)
+	}
+	// 匿名 WebSocket 客户端只协商普通子协议
+	if rec := serve(w, h, jwtRequest("Sec-WebSocket-Protocol", "graphql-ws")); rec.Code != http.StatusOK || called != 2 {
+		t.Fatalf("anonymous websocket status = %d, called %d", rec.Code, called)
+	}
+	// 携带了无效 token 时仍然拒绝
+	if rec := serve(w, h, jwtRequest("Authorization", "Bearer a.b.c")); rec.Code != http.StatusUnauthorized || called != 2 {
+		t.Fatalf("invalid token status = %d, called %d", rec.Code, called)
+	}
+}
+
+func TestJWTAuthEchoesSubprotocol(t *testing.T) {
+	w := New()
+	h := JWTAuth(auth.NewJWTVerifier(auth.StaticKey(jwtSecret)))(func(c *Context) {})
+
+	token := testJWT(t, jwt.RegisteredClaims{Subject: "user-1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))})
+	rec := serve(w, h, jwtRequest("Sec-WebSocket-Protocol", "Bearer, "+token))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Sec-WebSocket-Protocol"); got != "Bearer" {
+		t.Fatalf("Sec-WebSocket-Protocol = %q, want Bearer", got)
+	}
+}
+
+func TestJWTAuthWithSetAuthToken(t *testing.T) {
+	private, public, _ := writeKeyPair(t, t.TempDir(), "app")
+	verifier, err := fileVerifier(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := SetAuthToken("user-1", "water", private, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 旧版 SetAuthToken 只写入 jti
+	signer, _ := auth.NewJWTSigner(auth.HS256, jwtSecret)
+	legacy, _ := signer.Sign(jwt.RegisteredClaims{ID: "user-2", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))})
+
+	w := New()
+	srv := NewHandler(&echoService{}, ServerFilters(AuthenticatedFilter()))
+	for _, tt := range []struct {
+		name     string
+		verifier *auth.JWTVerifier
+		token    string
+		want     string
+	}{
+		{"SetAuthToken", verifier, token, "user-1"},
+		{"legacy jti only", auth.NewJWTVerifier(auth.StaticKey(jwtSecret)), legacy, "user-2"},
+	} {
+		var subject string
+		var filterErr error
+		h := JWTAuth(tt.verifier)(func(c *Context) {
+			subject = c.Subject()
+			_, filterErr = srv.ServerWater(c, &echoRequest{})
+		})
+
+		if rec := serve(w, h, jwtRequest("Authorization", "Bearer "+tt.token)); rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, body %s", tt.name, rec.Code, rec.Body)
+		}
+		if subject != tt.want || filterErr != nil {
+			t.Fatalf("%s: Subject() = %q, AuthenticatedFilter: %v", tt.name, subject, filterErr)
+		}
+	}
+}
@@ -1,19 +1,29 @@
 package water
 
 import (
+	"context"
 	"log/slog"
 
 	"github.com/go-water/water/logger"
 )
 
 var (
-	log = logger.NewLogger(logger.Level, logger.AddSource)
+	log = logger.NewLogger(logger.GlobalLevel(), logger.AddSource)
 )
 
+func init() {
+	logger.RegisterExtractor(contextAttrs)
+}
+
 func NewLogger() *slog.Logger {
 	return log
 }
 
+// SetLogger 替换框架默认日志，例如使用 logger.New 自定义输出和格式
+func SetLogger(l *slog.Logger) {
+	log = l
+}
+
 func Info(msg string, args ...any) {
 	log.Info(msg, args...)
 }
@@ -25,3 +35,24 @@ func Warn(msg string, args ...any) {
 func Error(msg string, args ...any) {
 	log.Error(msg, args...)
 }
+
+// contextAttrs 从 water.Context 中提取请求 ID、用户和路由，附加到 *Context 方法记录的日志
+func contextAttrs(ctx context.Context) []slog.Attr {
+	c, ok := FromContext(ctx)
+	if !ok || c.Request == nil {
+		return nil
+	}
+
+	var attrs []slog.Attr
+	if id := c.RequestID(); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if user := c.Subject(); user != "" {
+		attrs = append(attrs, slog.String("user", user))
+	}
+	if route := c.Route(); route != "" {
+		attrs = append(attrs, slog.String("route", route))
+	}
+
+	return attrs
+}
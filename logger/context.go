@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// ContextExtractor 从 context 中提取需要附加到日志的属性，例如请求 ID、用户、路由
+type ContextExtractor func(ctx context.Context) []slog.Attr
+
+var (
+	mu         sync.RWMutex
+	extractors []ContextExtractor
+)
+
+// RegisterExtractor 注册全局提取器，创建日志时没有指定提取器的 ContextHandler 都会使用
+func RegisterExtractor(fn ContextExtractor) {
+	mu.Lock()
+	defer mu.Unlock()
+	extractors = append(extractors, fn)
+}
+
+func registeredExtractors() []ContextExtractor {
+	mu.RLock()
+	defer mu.RUnlock()
+	return extractors
+}
+
+type attrsKey struct{}
+
+// WithAttrs 把属性保存到 context，通过 Logger.InfoContext 等方法记录时自动附加
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return context.WithValue(ctx, attrsKey{}, slices.Concat(existing, attrs))
+}
+
+func attrsFromContext(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// ContextHandler 在记录日志时从 context 中提取属性，已经通过 With 附加的同名属性不会重复输出
+// context 中的属性写入当前分组，因此只与当前分组内 With 附加的属性去重
+type ContextHandler struct {
+	handler    slog.Handler
+	extractors []ContextExtractor
+	keys       []string
+}
+
+// NewContextHandler extractors 为空时使用 RegisterExtractor 注册的提取器
+func NewContextHandler(h slog.Handler, extractors ...ContextExtractor) *ContextHandler {
+	return &ContextHandler{handler: h, extractors: extractors}
+}
+
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		extractors := h.extractors
+		if len(extractors) == 0 {
+			extractors = registeredExtractors()
+		}
+
+		h.addAttrs(&r, attrsFromContext(ctx))
+		for _, extract := range extractors {
+			h.addAttrs(&r, extract(ctx))
+		}
+	}
+
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *ContextHandler) addAttrs(r *slog.Record, attrs []slog.Attr) {
+	for _, attr := range attrs {
+		if slices.Contains(h.keys, attr.Key) {
+			continue
+		}
+		r.AddAttrs(attr)
+	}
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	keys := slices.Clone(h.keys)
+	for _, attr := range attrs {
+		keys = append(keys, attr.Key)
+	}
+
+	return &ContextHandler{handler: h.handler.WithAttrs(attrs), extractors: h.extractors, keys: keys}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &ContextHandler{handler: h.handler.WithGroup(name), extractors: h.extractors}
+}
@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func dropTime(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return a
+}
+
+func userExtractor(ctx context.Context) []slog.Attr {
+	if user, ok := ctx.Value(userKey{}).(string); ok {
+		return []slog.Attr{slog.String("user", user)}
+	}
+	return nil
+}
+
+type userKey struct{}
+
+func TestContextHandler(t *testing.T) {
+	ctx := WithAttrs(context.Background(), slog.String("request_id", "r1"))
+	ctx = context.WithValue(ctx, userKey{}, "u1")
+
+	tests := []struct {
+		name       string
+		extractors []ContextExtractor
+		logger     func(l *slog.Logger) *slog.Logger
+		ctx        context.Context
+		want       string
+	}{
+		{
+			name: "context attrs",
+			ctx:  ctx,
+			want: "level=INFO msg=hi request_id=r1",
+		},
+		{
+			name:       "extractor",
+			extractors: []ContextExtractor{userExtractor},
+			ctx:        ctx,
+			want:       "level=INFO msg=hi request_id=r1 user=u1",
+		},
+		{
+			name: "nil context",
+			ctx:  nil,
+			want: "level=INFO msg=hi",
+		},
+		{
+			name: "accumulated context attrs",
+			ctx:  WithAttrs(ctx, slog.String("route", "/users")),
+			want: "level=INFO msg=hi request_id=r1 route=/users",
+		},
+		{
+			name:       "with attrs dedupe",
+			extractors: []ContextExtractor{userExtractor},
+			logger:     func(l *slog.Logger) *slog.Logger { return l.With("request_id", "r0").With("user", "u0") },
+			ctx:        ctx,
+			want:       "level=INFO msg=hi request_id=r0 user=u0",
+		},
+		{
+			name:   "empty group",
+			logger: func(l *slog.Logger) *slog.Logger { return l.With("request_id", "r0").WithGroup("") },
+			ctx:    ctx,
+			want:   "level=INFO msg=hi request_id=r0",
+		},
+		{
+			name:   "with group",
+			logger: func(l *slog.Logger) *slog.Logger { return l.WithGroup("svc") },
+			ctx:    ctx,
+			want:   "level=INFO msg=hi svc.request_id=r1",
+		},
+		{
+			name: "with attrs then group",
+			logger: func(l *slog.Logger) *slog.Logger {
+				return l.With("request_id", "r0").WithGroup("svc").With("request_id", "r2")
+			},
+			ctx:  ctx,
+			want: "level=INFO msg=hi request_id=r0 svc.request_id=r2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := slog.New(NewContextHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{ReplaceAttr: dropTime}), tt.extractors...))
+			if tt.logger != nil {
+				l = tt.logger(l)
+			}
+
+			l.InfoContext(tt.ctx, "hi")
+			if got := strings.TrimSpace(buf.String()); got != tt.want {
+				t.Fatalf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterExtractor(t *testing.T) {
+	t.Cleanup(func() {
+		mu.Lock()
+		extractors = nil
+		mu.Unlock()
+	})
+	RegisterExtractor(userExtractor)
+
+	var buf bytes.Buffer
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{ReplaceAttr: dropTime})
+	ctx := context.WithValue(context.Background(), userKey{}, "u1")
+
+	slog.New(NewContextHandler(h)).InfoContext(ctx, "global")
+	// 指定了提取器时不使用全局注册的提取器
+	slog.New(NewContextHandler(h, func(context.Context) []slog.Attr { return nil })).InfoContext(ctx, "own")
+
+	want := "level=INFO msg=global user=u1\nlevel=INFO msg=own\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestNewFormat(t *testing.T) {
+	ctx := WithAttrs(context.Background(), slog.String("request_id", "r1"))
+	ctx = context.WithValue(ctx, userKey{}, "u1")
+
+	tests := []struct {
+		name  string
+		opts  Options
+		check func(t *testing.T, out string)
+	}{
+		{
+			name: "json by default",
+			opts: Options{Extractors: []ContextExtractor{userExtractor}},
+			check: func(t *testing.T, out string) {
+				var entry map[string]any
+				if err := json.Unmarshal([]byte(out), &entry); err != nil {
+					t.Fatalf("output is not JSON: %q", out)
+				}
+				if entry["msg"] != "hi" || entry["request_id"] != "r1" || entry["user"] != "u1" {
+					t.Fatalf("entry = %v", entry)
+				}
+			},
+		},
+		{
+			name: "text",
+			opts: Options{Format: FormatText, TimeFormat: "2006"},
+			check: func(t *testing.T, out string) {
+				if !strings.HasPrefix(out, "time=") || !strings.HasSuffix(out, "level=INFO msg=hi request_id=r1\n") {
+					t.Fatalf("output = %q", out)
+				}
+				if year := strings.Fields(out)[0]; len(year) != len("time=2006") {
+					t.Fatalf("TimeFormat not applied: %q", out)
+				}
+			},
+		},
+		{
+			name: "level",
+			opts: Options{Format: FormatText, Level: slog.LevelWarn},
+			check: func(t *testing.T, out string) {
+				if out != "" {
+					t.Fatalf("INFO logged at WARN level: %q", out)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.opts.Writer = &buf
+			New(tt.opts).InfoContext(ctx, "hi")
+			tt.check(t, buf.String())
+		})
+	}
+}
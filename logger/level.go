@@ -0,0 +1,231 @@
+package logger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// leveler 包装 slog.Leveler，便于原子替换不同类型的级别
+type leveler struct {
+	slog.Leveler
+}
+
+// dynamicLevel 设置过级别时使用设置的级别，否则调用 fallback
+type dynamicLevel struct {
+	v        atomic.Pointer[leveler]
+	fallback func() slog.Level
+}
+
+func (l *dynamicLevel) Level() slog.Level {
+	if v := l.v.Load(); v != nil {
+		return v.Level()
+	}
+
+	return l.fallback()
+}
+
+var (
+	// global 全局级别，SetLevel 设置后覆盖 Level
+	global = &dynamicLevel{fallback: func() slog.Level { return Level }}
+
+	serviceLevels sync.Map // map[string]*dynamicLevel (service name -> level)
+
+	pendingMu sync.Mutex
+	pendings  = make(map[string]*pendingLevel)
+)
+
+// pendingLevel 临时级别到期后的恢复任务
+type pendingLevel struct {
+	timer   *time.Timer
+	restore func()
+}
+
+// GlobalLevel 返回全局级别，未调用 SetLevel 时为 Level，运行时修改对已创建的日志立即生效
+func GlobalLevel() slog.Leveler {
+	return global
+}
+
+// SetLevel 运行时修改全局级别
+func SetLevel(level slog.Level) {
+	SetLevelFor("", level, 0)
+}
+
+// ServiceLevel 返回服务的级别，未单独设置时跟随全局级别，运行时修改对已创建的日志立即生效
+func ServiceLevel(name string) slog.Leveler {
+	level, _ := serviceLevels.LoadOrStore(name, &dynamicLevel{fallback: global.Level})
+	return level.(*dynamicLevel)
+}
+
+// lookupService 返回已经创建过的服务级别，不存在时不创建
+func lookupService(name string) (*dynamicLevel, bool) {
+	level, ok := serviceLevels.Load(name)
+	if !ok {
+		return nil, false
+	}
+
+	return level.(*dynamicLevel), true
+}
+
+// SetServiceLevel 为指定服务单独设置日志级别，level 可以是 *slog.LevelVar 等动态级别
+func SetServiceLevel(name string, level slog.Leveler) {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	cancelPending(name)
+	ServiceLevel(name).(*dynamicLevel).v.Store(&leveler{level})
+}
+
+// ResetServiceLevel 取消服务的单独设置，恢复跟随全局级别
+func ResetServiceLevel(name string) {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	cancelPending(name)
+	if l, ok := lookupService(name); ok {
+		l.v.Store(nil)
+	}
+}
+
+// SetLevelFor 设置级别，name 为空时设置全局级别，ttl 大于 0 时到期后恢复原来的设置，
+// 常用于临时打开 debug 日志排查问题
+func SetLevelFor(name string, level slog.Level, ttl time.Duration) {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+
+	l := global
+	if name != "" {
+		l = ServiceLevel(name).(*dynamicLevel)
+	}
+
+	previous := l.v.Load()
+	restore := func() { l.v.Store(previous) }
+	if p := cancelPending(name); p != nil {
+		// 覆盖尚未到期的临时级别时，仍然恢复到临时级别之前的设置
+		restore = p.restore
+	}
+	l.v.Store(&leveler{level})
+
+	if ttl > 0 {
+		p := &pendingLevel{restore: restore}
+		p.timer = time.AfterFunc(ttl, func() {
+			pendingMu.Lock()
+			defer pendingMu.Unlock()
+			if pendings[name] == p {
+				delete(pendings, name)
+				p.restore()
+			}
+		})
+		pendings[name] = p
+	}
+}
+
+func cancelPending(name string) *pendingLevel {
+	p, ok := pendings[name]
+	if !ok {
+		return nil
+	}
+
+	p.timer.Stop()
+	delete(pendings, name)
+	return p
+}
+
+// Levels 返回全局级别和所有单独设置过级别的服务
+func Levels() (globalLevel slog.Level, services map[string]slog.Level) {
+	services = make(map[string]slog.Level)
+	serviceLevels.Range(func(key, value any) bool {
+		if v := value.(*dynamicLevel).v.Load(); v != nil {
+			services[key.(string)] = v.Level()
+		}
+		return true
+	})
+
+	return global.Level(), services
+}
+
+type levelRequest struct {
+	Service string `json:"service"`
+	Level   string `json:"level"`
+	TTL     string `json:"ttl"`
+}
+
+type levelResponse struct {
+	Level    string            `json:"level"`
+	Services map[string]string `json:"services"`
+	Known    []string          `json:"known"`
+}
+
+// Handler 运行时查看和修改日志级别的管理接口，请挂载在受保护的路由下
+// GET 返回当前级别；PUT/POST {"service":"", "level":"DEBUG", "ttl":"10m"} 修改级别，
+// service 为空时修改全局级别，level 为空时取消服务的单独设置，ttl 到期后自动恢复；
+// service 只能是已经创建过日志的服务，未知的名称返回 404
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut, http.MethodPost:
+			var req levelRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"err": err.Error()})
+				return
+			}
+
+			if req.Service != "" {
+				if _, ok := lookupService(req.Service); !ok {
+					writeJSON(w, http.StatusNotFound, map[string]string{"err": "unknown service: " + req.Service})
+					return
+				}
+			}
+
+			var ttl time.Duration
+			if req.TTL != "" {
+				var err error
+				if ttl, err = time.ParseDuration(req.TTL); err != nil {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"err": err.Error()})
+					return
+				}
+			}
+
+			if req.Level == "" {
+				if req.Service == "" {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"err": "level is required"})
+					return
+				}
+				ResetServiceLevel(req.Service)
+				break
+			}
+
+			var level slog.Level
+			if err := level.UnmarshalText([]byte(req.Level)); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"err": err.Error()})
+				return
+			}
+			SetLevelFor(req.Service, level, ttl)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"err": "method not allowed"})
+			return
+		}
+
+		globalLevel, services := Levels()
+		resp := levelResponse{Level: globalLevel.String(), Services: make(map[string]string, len(services))}
+		for name, level := range services {
+			resp.Services[name] = level.String()
+		}
+		serviceLevels.Range(func(key, _ any) bool {
+			resp.Known = append(resp.Known, key.(string))
+			return true
+		})
+		slices.Sort(resp.Known)
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func resetLevels(t *testing.T) {
+	t.Cleanup(func() {
+		Level = slog.LevelInfo
+		global.v.Store(nil)
+		serviceLevels.Clear()
+	})
+}
+
+func TestLevelAssignment(t *testing.T) {
+	resetLevels(t)
+
+	// 兼容旧版在启动时直接赋值
+	Level = slog.LevelDebug
+	if got := GlobalLevel().Level(); got != slog.LevelDebug {
+		t.Fatalf("GlobalLevel() = %v, want DEBUG", got)
+	}
+
+	SetLevel(slog.LevelWarn)
+	if got := GlobalLevel().Level(); got != slog.LevelWarn {
+		t.Fatalf("GlobalLevel() after SetLevel = %v, want WARN", got)
+	}
+	if got := ServiceLevel("orders").Level(); got != slog.LevelWarn {
+		t.Fatalf("ServiceLevel() = %v, want global WARN", got)
+	}
+}
+
+func TestSetServiceLevel(t *testing.T) {
+	resetLevels(t)
+
+	var v slog.LevelVar
+	SetServiceLevel("orders", &v)
+	v.Set(slog.LevelError)
+	if got := ServiceLevel("orders").Level(); got != slog.LevelError {
+		t.Fatalf("ServiceLevel() = %v, want ERROR", got)
+	}
+
+	ResetServiceLevel("orders")
+	if got := ServiceLevel("orders").Level(); got != slog.LevelInfo {
+		t.Fatalf("ServiceLevel() after reset = %v, want INFO", got)
+	}
+}
+
+func TestSetLevelForRestores(t *testing.T) {
+	resetLevels(t)
+
+	SetServiceLevel("orders", slog.LevelWarn)
+	SetLevelFor("orders", slog.LevelDebug, 20*time.Millisecond)
+	if got := ServiceLevel("orders").Level(); got != slog.LevelDebug {
+		t.Fatalf("ServiceLevel() = %v, want DEBUG", got)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if got := ServiceLevel("orders").Level(); got != slog.LevelWarn {
+		t.Fatalf("ServiceLevel() after ttl = %v, want WARN", got)
+	}
+}
+
+func TestHandlerRejectsUnknownService(t *testing.T) {
+	resetLevels(t)
+	NewServiceLogger("orders")
+
+	put := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := put(`{"service":"ordres","level":"DEBUG"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown service status = %d, want 404", rec.Code)
+	}
+	if _, ok := lookupService("ordres"); ok {
+		t.Fatal("unknown service was registered")
+	}
+
+	if rec := put(`{"service":"orders","level":"DEBUG"}`); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if got := ServiceLevel("orders").Level(); got != slog.LevelDebug {
+		t.Fatalf("ServiceLevel() = %v, want DEBUG", got)
+	}
+}
@@ -1,22 +1,78 @@
 package logger
 
 import (
+	"io"
 	"log/slog"
 	"os"
 )
 
+type Format int
+
+const (
+	FormatJSON Format = iota
+	FormatText
+)
+
+// 包级默认配置，NewLogger 和 NewServiceLogger 使用
+// Level 为启动时的默认级别，运行时修改使用 SetLevel
 var (
-	Level     = slog.LevelInfo
-	AddSource bool
+	Level      = slog.LevelInfo
+	AddSource  bool
+	Writer     io.Writer = os.Stdout
+	Formatting           = FormatJSON
+	TimeFormat           = "2006-01-02 15:04:05"
 )
 
-func NewLogger(level slog.Leveler, addSource bool) *slog.Logger {
-	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level, AddSource: addSource, ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
-		v := a.Value
-		if v.Kind() == slog.KindTime {
-			return slog.String(a.Key, v.Time().Format("2006-01-02 15:04:05"))
+// Options 日志配置，TimeFormat 为空时使用 slog 默认的时间格式
+type Options struct {
+	Writer     io.Writer
+	Format     Format
+	Level      slog.Leveler
+	AddSource  bool
+	TimeFormat string
+	Extractors []ContextExtractor
+}
+
+// New 按配置创建日志，记录时会从 context 中提取属性，见 ContextHandler
+func New(opts Options) *slog.Logger {
+	if opts.Writer == nil {
+		opts.Writer = os.Stdout
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: opts.Level, AddSource: opts.AddSource}
+	if timeFormat := opts.TimeFormat; timeFormat != "" {
+		handlerOptions.ReplaceAttr = func(_ []string, a slog.Attr) slog.Attr {
+			v := a.Value
+			if v.Kind() == slog.KindTime {
+				return slog.String(a.Key, v.Time().Format(timeFormat))
+			}
+
+			return a
 		}
+	}
+
+	var h slog.Handler
+	switch opts.Format {
+	case FormatText:
+		h = slog.NewTextHandler(opts.Writer, handlerOptions)
+	default:
+		h = slog.NewJSONHandler(opts.Writer, handlerOptions)
+	}
+
+	return slog.New(NewContextHandler(h, opts.Extractors...))
+}
+
+func NewLogger(level slog.Leveler, addSource bool) *slog.Logger {
+	return New(Options{
+		Writer:     Writer,
+		Format:     Formatting,
+		Level:      level,
+		AddSource:  addSource,
+		TimeFormat: TimeFormat,
+	})
+}
 
-		return a
-	}}))
+// NewServiceLogger 创建带 name 属性的服务日志，级别由 ServiceLevel 决定
+func NewServiceLogger(name string) *slog.Logger {
+	return NewLogger(ServiceLevel(name), AddSource).With(slog.String("name", name))
 }
@@ -0,0 +1,107 @@
+package water
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-water/water/metrics"
+	"github.com/go-water/water/ratelimit"
+	"github.com/sony/gobreaker"
+)
+
+// 服务调用结果，作为 water_service_requests_total 的 outcome 标签
+const (
+	OutcomeSuccess     = "success"
+	OutcomeError       = "error"
+	OutcomeRejected    = "rejected"
+	OutcomeRateLimited = "rate_limited"
+	OutcomeBreakerOpen = "breaker_open"
+)
+
+// Metrics 按路由记录请求数、耗时和处理中的请求数，reg 为空时使用 metrics.Default，
+// 路由取 Context.Route，需通过 Use 注册才能拿到
+func Metrics(reg *metrics.Registry) Middleware {
+	if reg == nil {
+		reg = metrics.Default
+	}
+
+	requests := reg.Counter("water_http_requests_total", "Total number of HTTP requests.", "method", "route", "status")
+	duration := reg.Histogram("water_http_request_duration_seconds", "HTTP request latency in seconds.", nil, "method", "route")
+	inFlight := reg.Gauge("water_http_requests_in_flight", "Number of HTTP requests being served.", "method", "route")
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			method, route := c.Request.Method, c.Route()
+			inFlight.Inc(method, route)
+			begin := time.Now()
+			defer func() {
+				inFlight.Dec(method, route)
+				duration.Observe(time.Since(begin).Seconds(), method, route)
+				requests.Inc(method, route, strconv.Itoa(c.ResponseStatus()))
+			}()
+
+			next(c)
+		}
+	}
+}
+
+// ServerMetrics 按服务名记录调用结果、耗时，以及熔断器状态（0 关闭，1 半开，2 打开），reg 为空时使用 metrics.Default
+func ServerMetrics(reg *metrics.Registry) ServerOption {
+	return func(h *handler) {
+		if reg == nil {
+			reg = metrics.Default
+		}
+		h.registry = reg
+	}
+}
+
+type serviceMetrics struct {
+	name     string
+	requests *metrics.Counter
+	duration *metrics.Histogram
+}
+
+func newServiceMetrics(reg *metrics.Registry, name string, breaker *gobreaker.CircuitBreaker) *serviceMetrics {
+	m := &serviceMetrics{
+		name:     name,
+		requests: reg.Counter("water_service_requests_total", "Total number of service calls by outcome.", "service", "outcome"),
+		duration: reg.Histogram("water_service_duration_seconds", "Service call latency in seconds.", nil, "service"),
+	}
+
+	if breaker != nil {
+		state := reg.Gauge("water_service_breaker_state", "Circuit breaker state: 0 closed, 1 half-open, 2 open.", "service")
+		state.Func(func() float64 {
+			switch breaker.State() {
+			case gobreaker.StateHalfOpen:
+				return 1
+			case gobreaker.StateOpen:
+				return 2
+			default:
+				return 0
+			}
+		}, name)
+	}
+
+	return m
+}
+
+func (m *serviceMetrics) observe(err error, rejected bool, duration time.Duration) {
+	m.duration.Observe(duration.Seconds(), m.name)
+	m.requests.Inc(m.name, outcome(err, rejected))
+}
+
+func outcome(err error, rejected bool) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case rejected:
+		return OutcomeRejected
+	case errors.Is(err, ratelimit.ErrLimited):
+		return OutcomeRateLimited
+	case errors.Is(err, gobreaker.ErrOpenState), errors.Is(err, gobreaker.ErrTooManyRequests):
+		return OutcomeBreakerOpen
+	default:
+		return OutcomeError
+	}
+}
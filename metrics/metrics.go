@@ -0,0 +1,216 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets 默认的直方图分桶，单位秒
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type kind string
+
+const (
+	kindCounter   kind = "counter"
+	kindGauge     kind = "gauge"
+	kindHistogram kind = "histogram"
+)
+
+// family 同名指标，按标签值区分不同的序列
+type family struct {
+	name   string
+	help   string
+	kind   kind
+	labels []string
+	// buckets 直方图的分桶，其他类型为 nil
+	buckets []float64
+
+	mu     sync.RWMutex
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+
+	mu      sync.Mutex
+	value   float64
+	fn      func() float64
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func (f *family) with(values []string, buckets []float64) *series {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+	f.mu.RLock()
+	s, ok := f.series[key]
+	f.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok = f.series[key]; !ok {
+		s = &series{values: slices.Clone(values)}
+		if buckets != nil {
+			s.buckets = buckets
+			s.counts = make([]uint64, len(buckets))
+		}
+		f.series[key] = s
+	}
+
+	return s
+}
+
+// Counter 只增不减的计数器
+type Counter struct {
+	f *family
+}
+
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+func (c *Counter) Add(v float64, values ...string) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+
+	s := c.f.with(values, nil)
+	s.mu.Lock()
+	s.value += v
+	s.mu.Unlock()
+}
+
+// Gauge 可增可减的瞬时值
+type Gauge struct {
+	f *family
+}
+
+func (g *Gauge) Set(v float64, values ...string) {
+	s := g.f.with(values, nil)
+	s.mu.Lock()
+	s.value = v
+	s.mu.Unlock()
+}
+
+func (g *Gauge) Add(v float64, values ...string) {
+	s := g.f.with(values, nil)
+	s.mu.Lock()
+	s.value += v
+	s.mu.Unlock()
+}
+
+func (g *Gauge) Inc(values ...string) {
+	g.Add(1, values...)
+}
+
+func (g *Gauge) Dec(values ...string) {
+	g.Add(-1, values...)
+}
+
+// Func 采集时调用 fn 取值，适合熔断器状态等由外部持有的数据
+func (g *Gauge) Func(fn func() float64, values ...string) {
+	s := g.f.with(values, nil)
+	s.mu.Lock()
+	s.fn = fn
+	s.mu.Unlock()
+}
+
+// Histogram 按分桶统计观测值的分布
+type Histogram struct {
+	f       *family
+	buckets []float64
+}
+
+func (h *Histogram) Observe(v float64, values ...string) {
+	s := h.f.with(values, h.buckets)
+	i := sort.SearchFloat64s(s.buckets, v)
+	s.mu.Lock()
+	if i < len(s.counts) {
+		s.counts[i]++
+	}
+	s.sum += v
+	s.count++
+	s.mu.Unlock()
+}
+
+func (s *series) write(b *strings.Builder, f *family) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.kind != kindHistogram {
+		v := s.value
+		if s.fn != nil {
+			v = s.fn()
+		}
+		writeSample(b, f.name, f.labels, s.values, "", "", v)
+		return
+	}
+
+	var cumulative uint64
+	for i, upper := range s.buckets {
+		cumulative += s.counts[i]
+		writeSample(b, f.name+"_bucket", f.labels, s.values, "le", formatFloat(upper), float64(cumulative))
+	}
+	writeSample(b, f.name+"_bucket", f.labels, s.values, "le", "+Inf", float64(s.count))
+	writeSample(b, f.name+"_sum", f.labels, s.values, "", "", s.sum)
+	writeSample(b, f.name+"_count", f.labels, s.values, "", "", float64(s.count))
+}
+
+func writeSample(b *strings.Builder, name string, labels, values []string, extraLabel, extraValue string, v float64) {
+	b.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		b.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeLabel(b, label, values[i])
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				b.WriteByte(',')
+			}
+			writeLabel(b, extraLabel, extraValue)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(v))
+	b.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func writeLabel(b *strings.Builder, name, value string) {
+	b.WriteString(name)
+	b.WriteString(`="`)
+	labelEscaper.WriteString(b, value)
+	b.WriteByte('"')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func mustPanic(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s did not panic", name)
+		}
+	}()
+	fn()
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	reg := NewRegistry()
+	h := reg.Histogram("latency_seconds", "Latency.", []float64{1, 0.1, 0.5}, "route")
+	for _, v := range []float64{0.05, 0.1, 0.3, 2} {
+		h.Observe(v, "/users")
+	}
+
+	want := `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/users",le="0.1"} 2
+latency_seconds_bucket{route="/users",le="0.5"} 3
+latency_seconds_bucket{route="/users",le="1"} 3
+latency_seconds_bucket{route="/users",le="+Inf"} 4
+latency_seconds_sum{route="/users"} 2.45
+latency_seconds_count{route="/users"} 4
+`
+	if got := reg.String(); got != want {
+		t.Fatalf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	reg := NewRegistry()
+	reg.Counter("requests_total", "Requests.", "code").Inc("200")
+	// 同名同类型重复注册时共享序列
+	reg.Counter("requests_total", "Requests.", "code").Add(2, "200")
+	if got := reg.String(); !strings.Contains(got, `requests_total{code="200"} 3`) {
+		t.Fatalf("String() = %q", got)
+	}
+
+	reg.Histogram("latency_seconds", "", []float64{0.1, 1})
+	reg.Histogram("latency_seconds", "", []float64{1, 0.1})
+
+	mustPanic(t, "type mismatch", func() { reg.Gauge("requests_total", "", "code") })
+	mustPanic(t, "label mismatch", func() { reg.Counter("requests_total", "", "status") })
+	mustPanic(t, "bucket mismatch", func() { reg.Histogram("latency_seconds", "", []float64{0.5, 1}) })
+	mustPanic(t, "default bucket mismatch", func() { reg.Histogram("latency_seconds", "", nil) })
+	mustPanic(t, "label count", func() { reg.Counter("requests_total", "", "code").Inc() })
+	mustPanic(t, "negative counter", func() { reg.Counter("requests_total", "", "code").Add(-1, "200") })
+}
+
+func TestExposition(t *testing.T) {
+	reg := NewRegistry()
+	reg.Counter("b_total", "Line one\nback\\slash.", "path").Inc("a\"b\\c\nd")
+	state := 2.0
+	reg.Gauge("a_state", "", "service").Func(func() float64 { return state }, "svc")
+	// 没有序列的指标不输出
+	reg.Gauge("c_idle", "Idle.")
+
+	want := `# TYPE a_state gauge
+a_state{service="svc"} 2
+# HELP b_total Line one\nback\\slash.
+# TYPE b_total counter
+b_total{path="a\"b\\c\nd"} 1
+`
+	if got := reg.String(); got != want {
+		t.Fatalf("String() =\n%s\nwant\n%s", got, want)
+	}
+
+	state = 0
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest("GET", Path, nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), `a_state{service="svc"} 0`) {
+		t.Errorf("gauge func is not evaluated at scrape time: %s", rec.Body)
+	}
+}
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Path 指标接口的默认路径
+const Path = "/metrics"
+
+// Default 默认的注册表，框架内置的指标都注册在这里
+var Default = NewRegistry()
+
+// Registry 指标注册表，按 Prometheus 文本格式输出
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Counter 注册计数器，同名同类型的指标重复注册时返回已有的指标
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{f: r.register(name, help, kindCounter, labels, nil)}
+}
+
+// Gauge 注册瞬时值指标
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{f: r.register(name, help, kindGauge, labels, nil)}
+}
+
+// Histogram 注册直方图，buckets 为空时使用 DefBuckets，同名直方图的分桶不一致时 panic
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	f := r.register(name, help, kindHistogram, labels, buckets)
+	return &Histogram{f: f, buckets: f.buckets}
+}
+
+func (r *Registry) register(name, help string, k kind, labels []string, buckets []float64) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		if f.kind != k || !slices.Equal(f.labels, labels) {
+			panic(fmt.Sprintf("metrics: %s already registered as %s%v", name, f.kind, f.labels))
+		}
+		if !slices.Equal(f.buckets, buckets) {
+			panic(fmt.Sprintf("metrics: %s already registered with buckets %v", name, f.buckets))
+		}
+		return f
+	}
+
+	f := &family{
+		name:    name,
+		help:    help,
+		kind:    k,
+		labels:  slices.Clone(labels),
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+	r.families[name] = f
+
+	return f
+}
+
+// String 按 Prometheus 文本格式输出全部指标，指标和序列均按名称排序
+func (r *Registry) String() string {
+	r.mu.RLock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.RUnlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	var b strings.Builder
+	for _, f := range families {
+		f.mu.RLock()
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		all := make([]*series, len(keys))
+		for i, key := range keys {
+			all[i] = f.series[key]
+		}
+		f.mu.RUnlock()
+
+		if len(all) == 0 {
+			continue
+		}
+
+		if f.help != "" {
+			fmt.Fprintf(&b, "# HELP %s ", f.name)
+			helpEscaper.WriteString(&b, f.help)
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.kind)
+		for _, s := range all {
+			s.write(&b, f)
+		}
+	}
+
+	return b.String()
+}
+
+// Handler 返回输出指标的 http.Handler
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write([]byte(r.String()))
+	})
+}
+
+// Handler 返回输出 Default 中指标的 http.Handler
+func Handler() http.Handler {
+	return Default.Handler()
+}
@@ -0,0 +1,77 @@
+package water
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-water/water/metrics"
+	"github.com/sony/gobreaker"
+)
+
+func TestServerMetricsOutcomes(t *testing.T) {
+	reg := metrics.NewRegistry()
+	ctx := context.Background()
+
+	_, _ = NewHandler(&echoService{}, ServerMetrics(reg)).ServerWater(ctx, &echoRequest{})
+	_, _ = NewHandler(&echoService{}, ServerMetrics(reg), ServerFilters(AuthenticatedFilter())).ServerWater(ctx, &echoRequest{})
+
+	limited := NewHandler(&limitedService{}, ServerMetrics(reg), ServerErrorLimiter(time.Hour, 1))
+	for i := 0; i < 2; i++ {
+		_, _ = limited.ServerWater(ctx, &tracedRequest{})
+	}
+
+	breaker := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "limited",
+		Timeout:     time.Hour,
+		ReadyToTrip: func(counts gobreaker.Counts) bool { return counts.ConsecutiveFailures >= 1 },
+	})
+	tripped := NewHandler(&limitedService{}, ServerMetrics(reg), ServerBreaker(breaker))
+	if got := reg.String(); !strings.Contains(got, `water_service_breaker_state{service="limitedService"} 0`) {
+		t.Fatalf("closed breaker state missing:\n%s", got)
+	}
+	for i := 0; i < 2; i++ {
+		_, _ = tripped.ServerWater(ctx, &tracedRequest{})
+	}
+
+	got := reg.String()
+	for _, want := range []string{
+		`water_service_requests_total{service="echoService",outcome="success"} 1`,
+		`water_service_requests_total{service="echoService",outcome="rejected"} 1`,
+		`water_service_requests_total{service="limitedService",outcome="error"} 2`,
+		`water_service_requests_total{service="limitedService",outcome="rate_limited"} 1`,
+		`water_service_requests_total{service="limitedService",outcome="breaker_open"} 1`,
+		`water_service_duration_seconds_count{service="limitedService"} 4`,
+		`water_service_breaker_state{service="limitedService"} 2`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+	if t.Failed() {
+		t.Log(got)
+	}
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	reg := metrics.NewRegistry()
+	h := Metrics(reg)(func(c *Context) { c.Status(http.StatusCreated) })
+
+	req := httptest.NewRequest("POST", "/users", nil)
+	req.Pattern = "POST /users"
+	(&RouterHandler{wt: New(), h: h}).ServeHTTP(httptest.NewRecorder(), req)
+
+	got := reg.String()
+	for _, want := range []string{
+		`water_http_requests_total{method="POST",route="/users",status="201"} 1`,
+		`water_http_request_duration_seconds_count{method="POST",route="/users"} 1`,
+		`water_http_requests_in_flight{method="POST",route="/users"} 0`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics missing %s\n%s", want, got)
+		}
+	}
+}
@@ -33,6 +33,8 @@ func (r Render) Add(name string, tmpl *template.Template) {
 	if _, ok := r[name]; ok {
 		panic(fmt.Sprintf("template %s already exists", name))
 	}
+	// 保存未执行的副本，渲染时才能绑定 csrfField、cspNonce 等请求级模板函数
+	_ = render.RegisterTemplate(tmpl)
 	r[name] = tmpl
 }
 
@@ -11,20 +11,57 @@ import (
 
 type ServerOption func(h *handler)
 
-type Filter func(ctx context.Context) error
+func ServerFilterFunc(fn FilterFunc) ServerOption {
+	return func(h *handler) { h.filters = append(h.filters, fn().request()) }
+}
 
-type FilterFunc func() Filter
+// ServerFilters 按顺序执行过滤器，遇到第一个错误即返回
+func ServerFilters(filters ...Filter) ServerOption {
+	return func(h *handler) {
+		for _, filter := range filters {
+			h.filters = append(h.filters, filter.request())
+		}
+	}
+}
 
-func ServerFilterFunc(fn FilterFunc) ServerOption {
-	return func(h *handler) { h.filter = fn() }
+// ServerRequestFilters 与 ServerFilters 共用同一条过滤链，过滤器可读取请求对象
+func ServerRequestFilters(filters ...RequestFilter) ServerOption {
+	return func(h *handler) { h.filters = append(h.filters, filters...) }
 }
 
 type FinalizerFunc func(ctx context.Context, err error)
 
 func ServerFinalizer(f ...FinalizerFunc) ServerOption {
+	return func(h *handler) {
+		for _, fn := range f {
+			h.finalizer = append(h.finalizer, func(ctx context.Context, _, _ any, err error, _ time.Duration) {
+				fn(ctx, err)
+			})
+		}
+	}
+}
+
+// RequestFinalizerFunc 在请求结束后执行，可拿到请求、响应、错误和耗时，适合审计日志
+type RequestFinalizerFunc func(ctx context.Context, req, resp any, err error, duration time.Duration)
+
+func ServerRequestFinalizer(f ...RequestFinalizerFunc) ServerOption {
 	return func(h *handler) { h.finalizer = append(h.finalizer, f...) }
 }
 
+// BeforeFunc 在过滤器之前执行，返回的 ctx 会传给后续流程
+type BeforeFunc func(ctx context.Context, req any) context.Context
+
+func ServerBefore(before ...BeforeFunc) ServerOption {
+	return func(h *handler) { h.before = append(h.before, before...) }
+}
+
+// AfterFunc 在服务成功返回后执行，可以替换或补充响应
+type AfterFunc func(ctx context.Context, req, resp any) (any, error)
+
+func ServerAfter(after ...AfterFunc) ServerOption {
+	return func(h *handler) { h.after = append(h.after, after...) }
+}
+
 func ServerErrorLimiter(interval time.Duration, b int) ServerOption {
 	return func(h *handler) {
 		h.el = rate.NewLimiter(rate.Every(interval), b)
@@ -0,0 +1,97 @@
+package water
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// 常见平台写入真实客户端 IP 的请求头，配合 Water.TrustedPlatform 使用
+const (
+	PlatformCloudflare      = "CF-Connecting-IP"
+	PlatformGoogleAppEngine = "X-Appengine-Remote-Addr"
+	PlatformFlyIO           = "Fly-Client-IP"
+)
+
+var (
+	// CloudflareProxies Cloudflare 边缘节点地址段，见 https://www.cloudflare.com/ips/
+	CloudflareProxies = []string{
+		"173.245.48.0/20", "103.21.244.0/22", "103.22.200.0/22", "103.31.4.0/22",
+		"141.101.64.0/18", "108.162.192.0/18", "190.93.240.0/20", "188.114.96.0/20",
+		"197.234.240.0/22", "198.41.128.0/17", "162.158.0.0/15", "104.16.0.0/13",
+		"104.24.0.0/14", "172.64.0.0/13", "131.0.72.0/22",
+		"2400:cb00::/32", "2606:4700::/32", "2803:f800::/32", "2405:b500::/32",
+		"2405:8100::/32", "2a06:98c0::/29", "2c0f:f248::/32",
+	}
+
+	// GCPLoadBalancerProxies Google Cloud 负载均衡器和健康检查地址段
+	GCPLoadBalancerProxies = []string{"35.191.0.0/16", "130.211.0.0/22"}
+
+	// PrivateProxies 回环和私有网络地址段，适用于同机或内网反向代理
+	PrivateProxies = []string{"127.0.0.0/8", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "::1/128", "fc00::/7"}
+)
+
+// SetTrustedProxies 设置可信代理的 IP 或 CIDR，只有 RemoteIP 可信时才读取转发头部和 TrustedPlatform，
+// 默认不信任任何代理，ClientIP 直接返回 RemoteIP，部署在反向代理之后时需要显式设置；
+// 兼容旧版，未调用本方法时显式设置的 TrustedPlatform 对任意来源生效，调用后只对可信代理生效
+func (w *Water) SetTrustedProxies(proxies []string) error {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				return err
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return err
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	w.trustedProxies = prefixes
+	return nil
+}
+
+func (w *Water) isTrustedProxy(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range w.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor 按从左到右的顺序解析转发头部中的地址，Forwarded 头部按 RFC 7239 解析 for 参数
+func forwardedFor(headerName, header string) []string {
+	if !strings.EqualFold(headerName, "Forwarded") {
+		return strings.Split(header, ",")
+	}
+
+	var items []string
+	for _, element := range strings.Split(header, ",") {
+		for _, pair := range strings.Split(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || !strings.EqualFold(key, "for") {
+				continue
+			}
+
+			value = strings.Trim(value, `"`)
+			if strings.HasPrefix(value, "[") {
+				if end := strings.Index(value, "]"); end > 0 {
+					value = value[1:end]
+				}
+			} else if host, _, found := strings.Cut(value, ":"); found && strings.Count(value, ":") == 1 {
+				value = host
+			}
+			items = append(items, value)
+		}
+	}
+
+	return items
+}
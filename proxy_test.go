@@ -0,0 +1,96 @@
+package water
+
+import "testing"
+
+func TestClientIPIgnoresForwardedHeadersByDefault(t *testing.T) {
+	w := New()
+	c := newTestContext(w, "8.8.8.8:1234", map[string]string{
+		"X-Forwarded-For":  "1.2.3.4",
+		"X-Real-IP":        "1.2.3.4",
+		"CF-Connecting-IP": "1.2.3.4",
+	})
+
+	if ip := c.ClientIP(); ip != "8.8.8.8" {
+		t.Fatalf("ClientIP() = %q, want 8.8.8.8", ip)
+	}
+}
+
+func TestClientIPTrustedPlatformWithoutProxies(t *testing.T) {
+	w := New()
+	w.TrustedPlatform = PlatformCloudflare
+	c := newTestContext(w, "8.8.8.8:1234", map[string]string{
+		"X-Forwarded-For":  "5.6.7.8",
+		"CF-Connecting-IP": "1.2.3.4",
+	})
+
+	// 兼容旧版：显式设置的平台头部在没有配置可信代理时仍然生效，转发头部仍然忽略
+	if ip := c.ClientIP(); ip != "1.2.3.4" {
+		t.Fatalf("ClientIP() = %q, want 1.2.3.4", ip)
+	}
+
+	// 显式设置为空列表后不再信任任何来源
+	if err := w.SetTrustedProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	if ip := c.ClientIP(); ip != "8.8.8.8" {
+		t.Fatalf("ClientIP() with no trusted proxies = %q, want 8.8.8.8", ip)
+	}
+}
+
+func TestClientIPTrustedProxies(t *testing.T) {
+	w := New()
+	if err := w.SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		remote string
+		header map[string]string
+		want   string
+	}{
+		{"untrusted remote", "8.8.8.8:1", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "8.8.8.8"},
+		{"trusted remote", "10.0.0.1:1", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "1.2.3.4"},
+		{"skip trusted hops", "10.0.0.1:1", map[string]string{"X-Forwarded-For": "9.9.9.9, 1.2.3.4, 10.0.0.2, 192.168.1.1"}, "1.2.3.4"},
+		{"spoofed leftmost", "10.0.0.1:1", map[string]string{"X-Forwarded-For": "6.6.6.6, 1.2.3.4"}, "1.2.3.4"},
+		{"invalid header", "10.0.0.1:1", map[string]string{"X-Forwarded-For": "not-an-ip"}, "10.0.0.1"},
+		{"forwarded header", "10.0.0.1:1", map[string]string{"Forwarded": `for="[2001:db8::1]:4711"`}, "2001:db8::1"},
+		{"ipv4 mapped remote", "[::ffff:10.0.0.1]:1", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "1.2.3.4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestContext(w, tt.remote, tt.header)
+			if ip := c.ClientIP(); ip != tt.want {
+				t.Fatalf("ClientIP() = %q, want %q", ip, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPTrustedPlatform(t *testing.T) {
+	w := New()
+	w.TrustedPlatform = PlatformCloudflare
+	if err := w.SetTrustedProxies(CloudflareProxies); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestContext(w, "173.245.48.1:1", map[string]string{PlatformCloudflare: "1.2.3.4"})
+	if ip := c.ClientIP(); ip != "1.2.3.4" {
+		t.Fatalf("ClientIP() = %q, want 1.2.3.4", ip)
+	}
+
+	c = newTestContext(w, "8.8.8.8:1", map[string]string{PlatformCloudflare: "1.2.3.4"})
+	if ip := c.ClientIP(); ip != "8.8.8.8" {
+		t.Fatalf("ClientIP() from untrusted peer = %q, want 8.8.8.8", ip)
+	}
+}
+
+func TestSetTrustedProxiesInvalid(t *testing.T) {
+	w := New()
+	if err := w.SetTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatal("expected error for invalid CIDR")
+	}
+	if err := w.SetTrustedProxies([]string{"proxy.local"}); err == nil {
+		t.Fatal("expected error for hostname")
+	}
+}
@@ -3,6 +3,7 @@ package ratelimit
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -10,6 +11,9 @@ import (
 	"golang.org/x/time/rate"
 )
 
+// ErrLimited 请求被限流，各限流器返回的错误都包装了它，可用 errors.Is 判断
+var ErrLimited = errors.New("rate limit exceeded")
+
 type Allower interface {
 	Allow() bool
 }
@@ -18,7 +22,7 @@ func NewErrorLimiter(limit Allower) endpoint.Middleware {
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(ctx context.Context, request any) (any, error) {
 			if !limit.Allow() {
-				return nil, errors.New("rate limit exceeded")
+				return nil, ErrLimited
 			}
 
 			return next(ctx, request)
@@ -80,7 +84,7 @@ func (ibl *IPBasedLimiter) IPErrorLimiter(getIP func(ctx context.Context) string
 
 			limiter := ibl.getLimiter(ip)
 			if !limiter.Allow() {
-				return nil, errors.New("rate limit exceeded for IP: " + ip)
+				return nil, fmt.Errorf("%w for IP: %s", ErrLimited, ip)
 			}
 
 			return next(ctx, request)
@@ -144,7 +148,7 @@ func (ubl *UserBasedLimiter) UserErrorLimiter(getUserID func(ctx context.Context
 
 			limiter := ubl.getLimiter(userID)
 			if !limiter.Allow() {
-				return nil, errors.New("rate limit exceeded for user: " + userID)
+				return nil, fmt.Errorf("%w for user: %s", ErrLimited, userID)
 			}
 
 			return next(ctx, request)
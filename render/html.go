@@ -1,8 +1,10 @@
 package render
 
 import (
+	"fmt"
 	"html/template"
 	"net/http"
+	"sync"
 )
 
 var htmlContentType = []string{"text/html; charset=utf-8"}
@@ -13,20 +15,61 @@ type HTMLRender interface {
 }
 
 // HTML contains template reference and its name with given interface object.
+// Funcs are request scoped template functions, such as csrfField or cspNonce,
+// the template must have been parsed with placeholders of the same names.
 type HTML struct {
 	Template *template.Template
 	Name     string
 	Data     any
+	Funcs    template.FuncMap
+}
+
+// pristine holds an unexecuted copy of each registered template,
+// because html/template can not clone a template that has already been executed.
+var pristine sync.Map // map[*template.Template]*template.Template
+
+// RegisterTemplate keeps an unexecuted copy of t so that request scoped Funcs can be bound
+// on every render. It must be called before t is executed, multitemplate calls it in Add.
+func RegisterTemplate(t *template.Template) error {
+	base, err := t.Clone()
+	if err != nil {
+		return err
+	}
+
+	pristine.Store(t, base)
+	return nil
 }
 
 // Render (HTML) executes template and writes its result with custom ContentType for response.
 func (r HTML) Render(w http.ResponseWriter) error {
-	r.WriteContentType(w)
+	tmpl := r.Template
+	if len(r.Funcs) > 0 {
+		var err error
+		if tmpl, err = r.bind(); err != nil {
+			return err
+		}
+	}
 
+	r.WriteContentType(w)
 	if r.Name == "" {
-		return r.Template.Execute(w, r.Data)
+		return tmpl.Execute(w, r.Data)
+	}
+	return tmpl.ExecuteTemplate(w, r.Name, r.Data)
+}
+
+// bind clones the pristine copy and binds Funcs to the clone, the registered template itself is never executed.
+func (r HTML) bind() (*template.Template, error) {
+	base := r.Template
+	if v, ok := pristine.Load(r.Template); ok {
+		base = v.(*template.Template)
 	}
-	return r.Template.ExecuteTemplate(w, r.Name, r.Data)
+
+	tmpl, err := base.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("render: template %q needs render.RegisterTemplate before its first execution to use request funcs: %w", r.Template.Name(), err)
+	}
+
+	return tmpl.Funcs(r.Funcs), nil
 }
 
 // WriteContentType (HTML) writes HTML ContentType.
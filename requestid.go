@@ -0,0 +1,110 @@
+package water
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+type requestID struct {
+	header    string
+	generator func() string
+}
+
+type RequestIDOption func(r *requestID)
+
+// RequestIDHeaderName 读取和回写请求 ID 的头部，默认 X-Request-ID
+func RequestIDHeaderName(name string) RequestIDOption {
+	return func(r *requestID) { r.header = name }
+}
+
+func RequestIDGenerator(fn func() string) RequestIDOption {
+	return func(r *requestID) { r.generator = fn }
+}
+
+// RequestID 沿用请求中的 X-Request-ID 或 W3C traceparent 中的 trace-id，没有时生成新的 ID，
+// 保存到 Context 并回写到响应头，同时为当前请求创建带 request_id 属性的日志
+func RequestID(options ...RequestIDOption) Middleware {
+	r := &requestID{
+		header:    RequestIDHeader,
+		generator: newRequestID,
+	}
+	for _, option := range options {
+		option(r)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			id := c.GetHeader(r.header)
+			if !validRequestID(id) {
+				id = traceIDFromParent(c.GetHeader("traceparent"))
+			}
+			if id == "" {
+				id = r.generator()
+			}
+
+			c.Set(RequestIDKey, id)
+			c.Set(LoggerKey, log.With(slog.String("request_id", id)))
+			c.Header(r.header, id)
+			next(c)
+		}
+	}
+}
+
+// LoggerFromContext 返回当前请求的日志，没有使用 RequestID 中间件时返回默认日志
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if c, ok := FromContext(ctx); ok {
+		return c.Logger()
+	}
+
+	return log
+}
+
+// withRequestID 为 l 附加当前请求的 request_id
+func withRequestID(ctx context.Context, l *slog.Logger) *slog.Logger {
+	if c, ok := FromContext(ctx); ok {
+		if id := c.RequestID(); id != "" {
+			return l.With(slog.String("request_id", id))
+		}
+	}
+
+	return l
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// traceIDFromParent 解析 version-traceid-parentid-flags 格式，返回 32 位十六进制的 trace-id
+func traceIDFromParent(traceparent string) string {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 {
+		return ""
+	}
+
+	traceID := strings.ToLower(parts[1])
+	if _, err := hex.DecodeString(traceID); err != nil || traceID == strings.Repeat("0", 32) {
+		return ""
+	}
+
+	return traceID
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,60 @@
+package water
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	w := New()
+	var got string
+	h := RequestID()(func(c *Context) { got = c.RequestID() })
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"incoming", map[string]string{RequestIDHeader: "abc-123"}, "abc-123"},
+		{"traceparent", map[string]string{"traceparent": "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"invalid header falls back to traceparent", map[string]string{
+			RequestIDHeader: "bad id\n",
+			"traceparent":   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"too long", map[string]string{RequestIDHeader: strings.Repeat("a", 129)}, ""},
+		{"zero trace id", map[string]string{"traceparent": "00-00000000000000000000000000000000-00f067aa0ba902b7-01"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			rec := serve(w, h, req)
+			if tt.want != "" && got != tt.want {
+				t.Fatalf("RequestID() = %q, want %q", got, tt.want)
+			}
+			if tt.want == "" && len(got) != 32 {
+				t.Fatalf("RequestID() = %q, want a generated ID", got)
+			}
+			if rec.Header().Get(RequestIDHeader) != got {
+				t.Fatalf("response header = %q, want %q", rec.Header().Get(RequestIDHeader), got)
+			}
+		})
+	}
+}
+
+func TestRequestIDOptions(t *testing.T) {
+	w := New()
+	var got string
+	h := RequestID(RequestIDHeaderName("X-Correlation-ID"), RequestIDGenerator(func() string { return "generated" }))(func(c *Context) {
+		got = c.RequestID()
+	})
+
+	rec := serve(w, h, httptest.NewRequest("GET", "/", nil))
+	if got != "generated" || rec.Header().Get("X-Correlation-ID") != "generated" {
+		t.Fatalf("RequestID() = %q, header %q", got, rec.Header().Get("X-Correlation-ID"))
+	}
+}
@@ -0,0 +1,70 @@
+package water
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// responseWriter 记录状态码和写出字节数，并在首次写出前执行回调，
+// 供会话等需要在响应头发送前修改头部的中间件使用
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	size        int
+	written     bool
+	beforeWrite []func()
+}
+
+func (w *responseWriter) reset(writer http.ResponseWriter) {
+	w.ResponseWriter = writer
+	w.status = http.StatusOK
+	w.size = 0
+	w.written = false
+	clear(w.beforeWrite)
+	w.beforeWrite = w.beforeWrite[:0]
+}
+
+func (w *responseWriter) writeHeaderNow() {
+	if w.written {
+		return
+	}
+
+	w.written = true
+	for i := len(w.beforeWrite) - 1; i >= 0; i-- {
+		w.beforeWrite[i]()
+	}
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.written {
+		return
+	}
+
+	w.status = code
+	w.writeHeaderNow()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(data []byte) (n int, err error) {
+	w.writeHeaderNow()
+	n, err = w.ResponseWriter.Write(data)
+	w.size += n
+	return
+}
+
+func (w *responseWriter) Flush() {
+	w.writeHeaderNow()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.writeHeaderNow()
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,137 @@
+package water
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+)
+
+// RouteInfo 路由信息，Pattern 不含请求方法，Source 为注册位置
+type RouteInfo struct {
+	Method      string
+	Pattern     string
+	Scope       string
+	Middlewares int
+	Handler     string
+	Source      string
+}
+
+func (ri RouteInfo) String() string {
+	return ri.Method + " " + ri.Pattern + " (" + ri.Source + ")"
+}
+
+// registration 一条注册的路由，按注册顺序保存在 base.routes 中
+type registration struct {
+	RouteInfo
+	handler HandlerFunc
+}
+
+// RouteConflictError 路由重复注册或与已注册的路由冲突，ServeMux 无法区分两者
+type RouteConflictError struct {
+	Route     RouteInfo
+	Existing  RouteInfo
+	Duplicate bool
+}
+
+func (e *RouteConflictError) Error() string {
+	if e.Duplicate {
+		return fmt.Sprintf("route %s is already registered at %s", e.Route, e.Existing.Source)
+	}
+
+	return fmt.Sprintf("route %s conflicts with %s", e.Route, e.Existing)
+}
+
+// add 注册时检查路由：模式冲突时 panic；重复注册时，严格模式下 panic，否则记录警告并以后注册的为准
+func (b *base) add(rt *registration) {
+	for i, exist := range b.routes {
+		if exist.Method == rt.Method && exist.Pattern == rt.Pattern {
+			err := &RouteConflictError{Route: rt.RouteInfo, Existing: exist.RouteInfo, Duplicate: true}
+			if b.wt != nil && b.wt.StrictRoutes {
+				panic(err)
+			}
+
+			log.Warn(err.Error())
+			b.routes[i] = rt
+			return
+		}
+	}
+
+	if err := b.probe(rt.RouteInfo); err != nil {
+		panic(err)
+	}
+	b.routes = append(b.routes, rt)
+}
+
+// probe 把路由注册到探测用的 ServeMux，提前发现 Run 时才会出现的冲突，并找出冲突的那条路由
+func (b *base) probe(ri RouteInfo) error {
+	if b.mux == nil {
+		b.mux = new(http.ServeMux)
+	}
+
+	reason := tryHandle(b.mux, ri)
+	if reason == nil {
+		return nil
+	}
+	if invalid := tryHandle(new(http.ServeMux), ri); invalid != nil {
+		return fmt.Errorf("route %s: %v", ri, invalid)
+	}
+
+	for _, exist := range b.routes {
+		mux := new(http.ServeMux)
+		mux.Handle(exist.Method+" "+exist.Pattern, http.NotFoundHandler())
+		if tryHandle(mux, ri) != nil {
+			return &RouteConflictError{Route: ri, Existing: exist.RouteInfo}
+		}
+	}
+
+	return fmt.Errorf("route %s: %v", ri, reason)
+}
+
+func tryHandle(mux *http.ServeMux, ri RouteInfo) (reason any) {
+	defer func() { reason = recover() }()
+	mux.Handle(ri.Method+" "+ri.Pattern, http.NotFoundHandler())
+	return nil
+}
+
+// Routes 按注册顺序返回所有路由
+func (w *Water) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(w.base.routes))
+	for _, rt := range w.base.routes {
+		routes = append(routes, rt.RouteInfo)
+	}
+
+	return routes
+}
+
+func (w *Water) printRoutes(addr string) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, rt := range w.Routes() {
+		fmt.Fprintf(tw, "[WATER-debug] %s\t%s\t--> %s (%d middlewares)\n", rt.Method, rt.Pattern, rt.Handler, rt.Middlewares)
+	}
+	_ = tw.Flush()
+	fmt.Printf("[WATER-debug] Listening and serving HTTP on %s\n", addr)
+}
+
+func nameOfFunction(f any) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+// callerSource 返回框架外第一个调用者的位置，跳过 Router 和 Water 的注册方法
+func callerSource() string {
+	pc := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(2, pc)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/go-water/water.(*Router).") &&
+			!strings.HasPrefix(frame.Function, "github.com/go-water/water.(*Water).") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package water
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// mustPanic 返回 fn 中 panic 的值
+func mustPanic(t *testing.T, fn func()) (v any) {
+	t.Helper()
+	defer func() {
+		if v = recover(); v == nil {
+			t.Fatal("did not panic")
+		}
+	}()
+	fn()
+	return nil
+}
+
+func TestRoutes(t *testing.T) {
+	w := New()
+	w.Use(func(next HandlerFunc) HandlerFunc { return next })
+	w.GET("/users", func(c *Context) {})
+	api := w.Group("/api")
+	api.POST("/orders/", func(c *Context) {})
+
+	routes := w.Routes()
+	if len(routes) != 2 {
+		t.Fatalf("Routes() = %v", routes)
+	}
+	if got := routes[0]; got.Method != http.MethodGet || got.Pattern != "/users" || got.Middlewares != 1 {
+		t.Fatalf("routes[0] = %+v", got)
+	}
+	if got := routes[1]; got.Pattern != "/api/orders/{$}" || got.Scope != "/api" {
+		t.Fatalf("routes[1] = %+v", got)
+	}
+	// 注册位置为调用方，而不是框架内部
+	if !strings.Contains(routes[0].Source, "routes_test.go") {
+		t.Fatalf("Source = %q", routes[0].Source)
+	}
+}
+
+func TestDuplicateRouteReplaces(t *testing.T) {
+	w := New()
+	w.GET("/users", func(c *Context) { c.Text(http.StatusOK, "first") })
+	w.GET("/users", func(c *Context) { c.Text(http.StatusOK, "second") })
+
+	if routes := w.Routes(); len(routes) != 1 {
+		t.Fatalf("Routes() = %v", routes)
+	}
+	rec := serve(w, routeHandler(t, w, http.MethodGet, "/users"), httptest.NewRequest("GET", "/users", nil))
+	if rec.Body.String() != "second" {
+		t.Fatalf("body = %q, want the later registration", rec.Body)
+	}
+}
+
+func TestDuplicateRouteStrict(t *testing.T) {
+	w := New()
+	w.StrictRoutes = true
+	w.GET("/users", func(c *Context) {})
+
+	v := mustPanic(t, func() { w.GET("/users", func(c *Context) {}) })
+	var err *RouteConflictError
+	if !errors.As(v.(error), &err) || !err.Duplicate {
+		t.Fatalf("panic = %v, want a duplicate *RouteConflictError", v)
+	}
+	if !strings.Contains(err.Error(), "routes_test.go") {
+		t.Fatalf("error %q does not name the first registration", err)
+	}
+}
+
+func TestConflictingRoutePanics(t *testing.T) {
+	w := New()
+	w.GET("/users/{id}/posts", func(c *Context) {})
+	w.GET("/users/{id}", func(c *Context) {})
+
+	v := mustPanic(t, func() { w.GET("/{kind}/new/posts", func(c *Context) {}) })
+	var err *RouteConflictError
+	if !errors.As(v.(error), &err) || err.Duplicate {
+		t.Fatalf("panic = %v, want a conflict *RouteConflictError", v)
+	}
+	if err.Existing.Pattern != "/users/{id}/posts" || err.Route.Pattern != "/{kind}/new/posts" {
+		t.Fatalf("conflict between %s and %s", err.Route, err.Existing)
+	}
+	if len(w.Routes()) != 2 {
+		t.Fatal("conflicting route was registered")
+	}
+}
+
+func TestInvalidRoutePanics(t *testing.T) {
+	w := New()
+	w.GET("/users", func(c *Context) {})
+
+	v := mustPanic(t, func() { w.GET("/users/{id", func(c *Context) {}) })
+	var err *RouteConflictError
+	if errors.As(v.(error), &err) {
+		t.Fatalf("invalid pattern reported as a conflict: %v", v)
+	}
+	if !strings.Contains(v.(error).Error(), "/users/{id") {
+		t.Fatalf("panic = %v, want the invalid pattern", v)
+	}
+}
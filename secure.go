@@ -0,0 +1,222 @@
+package water
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// CSPNonce 在 CSP 指令中代表当前请求的 nonce，渲染时替换为 'nonce-xxx'
+const CSPNonce = "{nonce}"
+
+// CSP Content-Security-Policy 构造器，指令按添加顺序输出
+type CSP struct {
+	directives []string
+	sources    map[string][]string
+	reportOnly bool
+}
+
+func NewCSP() *CSP {
+	return &CSP{sources: make(map[string][]string)}
+}
+
+// Directive 添加指令，sources 中可以使用 CSPNonce
+func (p *CSP) Directive(name string, sources ...string) *CSP {
+	if _, ok := p.sources[name]; !ok {
+		p.directives = append(p.directives, name)
+	}
+	p.sources[name] = append(p.sources[name], sources...)
+	return p
+}
+
+func (p *CSP) DefaultSrc(sources ...string) *CSP {
+	return p.Directive("default-src", sources...)
+}
+
+func (p *CSP) ScriptSrc(sources ...string) *CSP {
+	return p.Directive("script-src", sources...)
+}
+
+func (p *CSP) StyleSrc(sources ...string) *CSP {
+	return p.Directive("style-src", sources...)
+}
+
+func (p *CSP) ImgSrc(sources ...string) *CSP {
+	return p.Directive("img-src", sources...)
+}
+
+func (p *CSP) ConnectSrc(sources ...string) *CSP {
+	return p.Directive("connect-src", sources...)
+}
+
+func (p *CSP) FrameAncestors(sources ...string) *CSP {
+	return p.Directive("frame-ancestors", sources...)
+}
+
+func (p *CSP) ReportURI(uri string) *CSP {
+	return p.Directive("report-uri", uri)
+}
+
+// ReportOnly 使用 Content-Security-Policy-Report-Only 头部
+func (p *CSP) ReportOnly() *CSP {
+	p.reportOnly = true
+	return p
+}
+
+func (p *CSP) usesNonce() bool {
+	for _, sources := range p.sources {
+		for _, source := range sources {
+			if source == CSPNonce {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String 输出策略，nonce 为空时去掉 CSPNonce
+func (p *CSP) String(nonce string) string {
+	parts := make([]string, 0, len(p.directives))
+	for _, name := range p.directives {
+		values := []string{name}
+		for _, source := range p.sources[name] {
+			if source == CSPNonce {
+				if nonce == "" {
+					continue
+				}
+				source = "'nonce-" + nonce + "'"
+			}
+			values = append(values, source)
+		}
+		parts = append(parts, strings.Join(values, " "))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+func (p *CSP) header() string {
+	if p.reportOnly {
+		return "Content-Security-Policy-Report-Only"
+	}
+	return "Content-Security-Policy"
+}
+
+type secureHeaders struct {
+	headers map[string]string
+	csp     *CSP
+}
+
+type SecureOption func(s *secureHeaders)
+
+// SecureHSTS Strict-Transport-Security，maxAge 为 0 时不输出
+func SecureHSTS(maxAge time.Duration, includeSubDomains, preload bool) SecureOption {
+	return func(s *secureHeaders) {
+		if maxAge <= 0 {
+			delete(s.headers, "Strict-Transport-Security")
+			return
+		}
+
+		value := fmt.Sprintf("max-age=%d", int(maxAge.Seconds()))
+		if includeSubDomains {
+			value += "; includeSubDomains"
+		}
+		if preload {
+			value += "; preload"
+		}
+		s.headers["Strict-Transport-Security"] = value
+	}
+}
+
+// SecureFrameOptions X-Frame-Options，默认 DENY，为空时不输出
+func SecureFrameOptions(value string) SecureOption {
+	return func(s *secureHeaders) { s.header("X-Frame-Options", value) }
+}
+
+// SecureReferrerPolicy 默认 strict-origin-when-cross-origin
+func SecureReferrerPolicy(value string) SecureOption {
+	return func(s *secureHeaders) { s.header("Referrer-Policy", value) }
+}
+
+// SecurePermissionsPolicy 例如 "camera=(), microphone=()"
+func SecurePermissionsPolicy(value string) SecureOption {
+	return func(s *secureHeaders) { s.header("Permissions-Policy", value) }
+}
+
+// SecureHeader 设置其它自定义头部，value 为空时不输出
+func SecureHeader(name, value string) SecureOption {
+	return func(s *secureHeaders) { s.header(name, value) }
+}
+
+// SecureCSP 设置 Content-Security-Policy，策略中使用 CSPNonce 时每个请求生成新的 nonce，
+// 模板中使用 <script nonce="{{ cspNonce }}">（解析时加入 TemplateFuncs），也可以通过 Context.CSPNonce 读取后放入模板数据
+func SecureCSP(csp *CSP) SecureOption {
+	return func(s *secureHeaders) { s.csp = csp }
+}
+
+func (s *secureHeaders) header(name, value string) {
+	if value == "" {
+		delete(s.headers, name)
+		return
+	}
+	s.headers[name] = value
+}
+
+// SecureHeaders 安全响应头中间件，默认输出 HSTS（仅 https 请求）、X-Content-Type-Options、
+// X-Frame-Options 和 Referrer-Policy
+func SecureHeaders(options ...SecureOption) Middleware {
+	s := &secureHeaders{headers: map[string]string{
+		"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "DENY",
+		"Referrer-Policy":           "strict-origin-when-cross-origin",
+	}}
+	for _, option := range options {
+		option(s)
+	}
+
+	nonce := s.csp != nil && s.csp.usesNonce()
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			header := c.Writer.Header()
+			for name, value := range s.headers {
+				if name == "Strict-Transport-Security" && !c.isHTTPS() {
+					continue
+				}
+				header.Set(name, value)
+			}
+
+			if s.csp != nil {
+				value := ""
+				if nonce {
+					value = newCSPNonce()
+					c.Set(CSPNonceKey, value)
+				}
+				header.Set(s.csp.header(), s.csp.String(value))
+			}
+
+			next(c)
+		}
+	}
+}
+
+func newCSPNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// isHTTPS 请求是否为 https，X-Forwarded-Proto 只在 RemoteIP 为可信代理时采用
+func (c *Context) isHTTPS() bool {
+	if c.Request.TLS != nil {
+		return true
+	}
+
+	remoteIP, err := netip.ParseAddr(c.RemoteIP())
+	if err != nil || !c.wt.isTrustedProxy(remoteIP) {
+		return false
+	}
+	return strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+}
@@ -0,0 +1,85 @@
+package water
+
+import (
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-water/water/multitemplate"
+)
+
+func TestSecureHeadersHSTSFromTrustedProxyOnly(t *testing.T) {
+	w := New()
+	if err := w.SetTrustedProxies(PrivateProxies); err != nil {
+		t.Fatal(err)
+	}
+	h := SecureHeaders()(func(c *Context) {})
+
+	tests := []struct {
+		remote string
+		want   bool
+	}{
+		{"10.0.0.1:1", true},
+		{"8.8.8.8:1", false},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		c := newTestContext(w, tt.remote, map[string]string{"X-Forwarded-Proto": "https"})
+		c.w.reset(rec)
+		c.Writer = &c.w
+		h(c)
+
+		if got := rec.Header().Get("Strict-Transport-Security") != ""; got != tt.want {
+			t.Errorf("remote %s: HSTS set = %v, want %v", tt.remote, got, tt.want)
+		}
+	}
+}
+
+func TestSecureHeadersCSPNonce(t *testing.T) {
+	csp := NewCSP().DefaultSrc("'self'").ScriptSrc("'self'", CSPNonce)
+	var nonces []string
+	h := SecureHeaders(SecureCSP(csp))(func(c *Context) { nonces = append(nonces, c.CSPNonce()) })
+
+	w := New()
+	var headers []string
+	for i := 0; i < 2; i++ {
+		rec := serve(w, h, httptest.NewRequest("GET", "/", nil))
+		headers = append(headers, rec.Header().Get("Content-Security-Policy"))
+	}
+
+	if nonces[0] == "" || nonces[0] == nonces[1] {
+		t.Fatalf("nonces = %q, want distinct non-empty values", nonces)
+	}
+	for i, header := range headers {
+		if !strings.Contains(header, "'nonce-"+nonces[i]+"'") {
+			t.Errorf("CSP %q does not contain nonce %q", header, nonces[i])
+		}
+	}
+}
+
+func TestSecureHeadersCSPNonceTemplateFunc(t *testing.T) {
+	views := multitemplate.New()
+	views.AddFromStringsFuncs("page", TemplateFuncs(), `<script nonce="{{ cspNonce }}"></script>`)
+	w := New()
+	w.HTMLRender = views
+
+	csp := NewCSP().ScriptSrc(CSPNonce)
+	var nonce string
+	h := SecureHeaders(SecureCSP(csp))(func(c *Context) {
+		nonce = c.CSPNonce()
+		c.HTML(http.StatusOK, "page", nil)
+	})
+
+	for i := 0; i < 2; i++ {
+		rec := serve(w, h, httptest.NewRequest("GET", "/", nil))
+		// html/template 会转义属性中的 +，浏览器解码后与响应头中的 nonce 一致
+		if want := `<script nonce="` + nonce + `"></script>`; html.UnescapeString(rec.Body.String()) != want {
+			t.Fatalf("render %d body = %q, want %q", i, rec.Body.String(), want)
+		}
+		if !strings.Contains(rec.Header().Get("Content-Security-Policy"), "'nonce-"+nonce+"'") {
+			t.Fatalf("CSP header does not contain the rendered nonce")
+		}
+	}
+}
@@ -32,6 +32,11 @@ func (s *ServerBase) GetLogger() *slog.Logger {
 	return s.l
 }
 
+// Logger 返回附加了当前请求 request_id 的服务日志
+func (s *ServerBase) Logger(ctx context.Context) *slog.Logger {
+	return withRequestID(ctx, s.l)
+}
+
 func (s *ServerBase) SetLogger(l *slog.Logger) {
 	s.l = l
 }
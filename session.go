@@ -0,0 +1,52 @@
+package water
+
+import (
+	"github.com/go-water/water/sessions"
+)
+
+type sessionState struct {
+	name    string
+	store   sessions.Store
+	session *sessions.Session
+	saved   bool
+}
+
+func (s *sessionState) load(c *Context) *sessions.Session {
+	if s.session == nil {
+		session, err := s.store.Get(c.Request, s.name)
+		if err != nil {
+			log.Error("load session failed", "name", s.name, "err", err.Error())
+		}
+		s.session = session
+	}
+
+	return s.session
+}
+
+func (s *sessionState) save(c *Context) {
+	if s.saved || s.session == nil || !s.session.Changed() {
+		return
+	}
+
+	s.saved = true
+	if c.sameSite != 0 {
+		s.session.Options.SameSite = c.sameSite
+	}
+	if err := s.store.Save(c.Writer, c.Request, s.session); err != nil {
+		log.Error("save session failed", "name", s.name, "err", err.Error())
+	}
+}
+
+// Sessions 会话中间件，通过 Context.Session 读写会话，会话在响应头写出前自动保存
+func Sessions(name string, store sessions.Store) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			state := &sessionState{name: name, store: store}
+			c.Set(SessionKey, state)
+			c.beforeWrite(func() { state.save(c) })
+
+			next(c)
+			state.save(c)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package sessions
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidValue = errors.New("sessions: invalid cookie value")
+	ErrExpiredValue = errors.New("sessions: expired cookie value")
+	ErrValueTooLong = errors.New("sessions: cookie value too long")
+)
+
+const maxCookieLength = 4096
+
+// Codec 对 cookie 值签名（HMAC-SHA256），blockKey 非空时再用 AES-GCM 加密
+type Codec struct {
+	hashKey []byte
+	aead    cipher.AEAD
+}
+
+// NewCodec blockKey 长度为 16、24 或 32 字节，为 nil 时只签名不加密
+func NewCodec(hashKey, blockKey []byte) (*Codec, error) {
+	if len(hashKey) == 0 {
+		return nil, errors.New("sessions: hash key is required")
+	}
+
+	c := &Codec{hashKey: hashKey}
+	if blockKey != nil {
+		block, err := aes.NewCipher(blockKey)
+		if err != nil {
+			return nil, err
+		}
+		if c.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Codec) Encode(name string, data []byte) (string, error) {
+	if c.aead != nil {
+		nonce := make([]byte, c.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		data = c.aead.Seal(nonce, nonce, data, []byte(name))
+	}
+
+	payload := strconv.FormatInt(time.Now().Unix(), 10) + "|" + base64.RawURLEncoding.EncodeToString(data)
+	value := payload + "|" + base64.RawURLEncoding.EncodeToString(c.mac(name, payload))
+	if len(value) > maxCookieLength {
+		return "", ErrValueTooLong
+	}
+
+	return value, nil
+}
+
+// Decode 校验签名和签发时间，maxAge 为 0 时不检查过期
+func (c *Codec) Decode(name, value string, maxAge time.Duration) ([]byte, error) {
+	i := strings.LastIndexByte(value, '|')
+	if i < 0 {
+		return nil, ErrInvalidValue
+	}
+
+	payload := value[:i]
+	mac, err := base64.RawURLEncoding.DecodeString(value[i+1:])
+	if err != nil || !hmac.Equal(mac, c.mac(name, payload)) {
+		return nil, ErrInvalidValue
+	}
+
+	ts, encoded, ok := strings.Cut(payload, "|")
+	if !ok {
+		return nil, ErrInvalidValue
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidValue
+	}
+	if maxAge > 0 && time.Since(time.Unix(unix, 0)) > maxAge {
+		return nil, ErrExpiredValue
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidValue
+	}
+
+	if c.aead != nil {
+		size := c.aead.NonceSize()
+		if len(data) < size {
+			return nil, ErrInvalidValue
+		}
+		if data, err = c.aead.Open(nil, data[:size], data[size:], []byte(name)); err != nil {
+			return nil, ErrInvalidValue
+		}
+	}
+
+	return data, nil
+}
+
+func (c *Codec) mac(name, payload string) []byte {
+	h := hmac.New(sha256.New, c.hashKey)
+	h.Write([]byte(name))
+	h.Write([]byte{'|'})
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
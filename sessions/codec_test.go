@@ -0,0 +1,85 @@
+package sessions
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testHashKey  = []byte("hash-key-hash-key-hash-key-32byt")
+	testBlockKey = []byte("block-key-16byte")
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	for name, blockKey := range map[string][]byte{"signed": nil, "encrypted": testBlockKey} {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewCodec(testHashKey, blockKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			value, err := c.Encode("sid", []byte("secret data"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := c.Decode("sid", value, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "secret data" {
+				t.Fatalf("Decode() = %q", data)
+			}
+		})
+	}
+}
+
+func TestCodecEncrypts(t *testing.T) {
+	c, _ := NewCodec(testHashKey, testBlockKey)
+	first, _ := c.Encode("sid", []byte("secret data"))
+	second, _ := c.Encode("sid", []byte("secret data"))
+
+	if strings.Contains(first, "c2VjcmV0IGRhdGE") {
+		t.Fatal("encrypted value contains the base64 plaintext")
+	}
+	if first == second {
+		t.Fatal("encrypting twice produced the same value")
+	}
+}
+
+func TestCodecRejects(t *testing.T) {
+	c, _ := NewCodec(testHashKey, testBlockKey)
+	value, _ := c.Encode("sid", []byte("secret data"))
+	other, _ := NewCodec([]byte("another-hash-key"), testBlockKey)
+
+	tamper := []byte(value)
+	tamper[len(value)/2] ^= 1
+
+	tests := map[string]func() error{
+		"tampered":   func() error { _, err := c.Decode("sid", string(tamper), 0); return err },
+		"other name": func() error { _, err := c.Decode("other", value, 0); return err },
+		"other key":  func() error { _, err := other.Decode("sid", value, 0); return err },
+		"garbage":    func() error { _, err := c.Decode("sid", "garbage", 0); return err },
+	}
+	for name, decode := range tests {
+		if err := decode(); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("%s: %v, want ErrInvalidValue", name, err)
+		}
+	}
+
+	// 时间戳在签名内，过期判断基于签发时间
+	old, _ := c.Encode("sid", []byte("secret data"))
+	time.Sleep(1100 * time.Millisecond)
+	if _, err := c.Decode("sid", old, time.Millisecond); !errors.Is(err, ErrExpiredValue) {
+		t.Fatalf("expired: %v, want ErrExpiredValue", err)
+	}
+}
+
+func TestCodecValueTooLong(t *testing.T) {
+	c, _ := NewCodec(testHashKey, nil)
+	if _, err := c.Encode("sid", bytes.Repeat([]byte("a"), maxCookieLength)); !errors.Is(err, ErrValueTooLong) {
+		t.Fatalf("Encode() = %v, want ErrValueTooLong", err)
+	}
+}
@@ -0,0 +1,156 @@
+package sessions
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"net/http"
+	"time"
+)
+
+const flashKey = "_flash"
+
+func init() {
+	gob.Register([]any{})
+	gob.Register(map[string]any{})
+}
+
+// Options 会话 cookie 的属性
+type Options struct {
+	Path     string
+	Domain   string
+	MaxAge   int
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+}
+
+// DefaultOptions 默认保存 7 天，HttpOnly，SameSite=Lax
+func DefaultOptions() *Options {
+	return &Options{
+		Path:     "/",
+		MaxAge:   7 * 24 * 3600,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// Session 值通过 gob 编码保存，自定义类型需要先 gob.Register
+type Session struct {
+	ID      string
+	Values  map[string]any
+	Options *Options
+	IsNew   bool
+
+	name    string
+	changed bool
+	oldID   string
+}
+
+func NewSession(name string, options *Options) *Session {
+	opts := *options
+	return &Session{
+		ID:      newID(),
+		Values:  make(map[string]any),
+		Options: &opts,
+		IsNew:   true,
+		name:    name,
+	}
+}
+
+func (s *Session) Name() string {
+	return s.name
+}
+
+// Changed 本次请求是否修改过会话
+func (s *Session) Changed() bool {
+	return s.changed
+}
+
+func (s *Session) Get(key string) any {
+	return s.Values[key]
+}
+
+func (s *Session) Set(key string, value any) {
+	s.Values[key] = value
+	s.changed = true
+}
+
+func (s *Session) Delete(key string) {
+	delete(s.Values, key)
+	s.changed = true
+}
+
+func (s *Session) Clear() {
+	clear(s.Values)
+	s.changed = true
+}
+
+// AddFlash 添加一次性消息，下次调用 Flashes 时读取并清除
+func (s *Session) AddFlash(value any) {
+	flashes, _ := s.Values[flashKey].([]any)
+	s.Values[flashKey] = append(flashes, value)
+	s.changed = true
+}
+
+func (s *Session) Flashes() []any {
+	flashes, _ := s.Values[flashKey].([]any)
+	if len(flashes) > 0 {
+		delete(s.Values, flashKey)
+		s.changed = true
+	}
+
+	return flashes
+}
+
+// RotateID 更换会话 ID，登录等提权操作后调用以防止会话固定攻击
+func (s *Session) RotateID() {
+	if s.oldID == "" && !s.IsNew {
+		s.oldID = s.ID
+	}
+	s.ID = newID()
+	s.changed = true
+}
+
+// Destroy 删除会话，保存时清除 cookie
+func (s *Session) Destroy() {
+	clear(s.Values)
+	s.Options.MaxAge = -1
+	s.changed = true
+}
+
+func (s *Session) expiry() time.Duration {
+	return time.Duration(s.Options.MaxAge) * time.Second
+}
+
+func (s *Session) cookie(value string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     s.name,
+		Value:    value,
+		Path:     s.Options.Path,
+		Domain:   s.Options.Domain,
+		MaxAge:   s.Options.MaxAge,
+		Secure:   s.Options.Secure,
+		HttpOnly: s.Options.HttpOnly,
+		SameSite: s.Options.SameSite,
+	}
+	if s.Options.MaxAge > 0 {
+		cookie.Expires = time.Now().Add(s.expiry())
+	} else if s.Options.MaxAge < 0 {
+		cookie.Expires = time.Unix(1, 0)
+	}
+
+	return cookie
+}
+
+// Store 会话存储，Get 在会话不存在或无效时返回新会话
+type Store interface {
+	Get(r *http.Request, name string) (*Session, error)
+	Save(w http.ResponseWriter, r *http.Request, s *Session) error
+}
+
+func newID() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
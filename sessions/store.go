@@ -0,0 +1,219 @@
+package sessions
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrNotFound = errors.New("sessions: session not found")
+
+type cookieData struct {
+	ID     string
+	Values map[string]any
+}
+
+// CookieStore 把会话内容签名（可选加密）后整体保存在 cookie 中
+type CookieStore struct {
+	Options *Options
+	codec   *Codec
+}
+
+func NewCookieStore(hashKey, blockKey []byte) (*CookieStore, error) {
+	codec, err := NewCodec(hashKey, blockKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CookieStore{Options: DefaultOptions(), codec: codec}, nil
+}
+
+func (s *CookieStore) Get(r *http.Request, name string) (*Session, error) {
+	session := NewSession(name, s.Options)
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+
+	data, err := s.codec.Decode(name, cookie.Value, session.expiry())
+	if err != nil {
+		return session, nil
+	}
+
+	var cd cookieData
+	if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&cd); err != nil {
+		return session, nil
+	}
+
+	session.ID, session.IsNew = cd.ID, false
+	if cd.Values != nil {
+		session.Values = cd.Values
+	}
+	return session, nil
+}
+
+func (s *CookieStore) Save(w http.ResponseWriter, _ *http.Request, session *Session) error {
+	if session.Options.MaxAge < 0 {
+		http.SetCookie(w, session.cookie(""))
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cookieData{ID: session.ID, Values: session.Values}); err != nil {
+		return err
+	}
+
+	value, err := s.codec.Encode(session.name, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, session.cookie(value))
+	return nil
+}
+
+// Backend 服务端会话数据存储，Load 在会话不存在或已过期时返回 ErrNotFound
+type Backend interface {
+	Load(ctx context.Context, id string) ([]byte, error)
+	Save(ctx context.Context, id string, data []byte, ttl time.Duration) error
+	Delete(ctx context.Context, id string) error
+}
+
+// ServerStore cookie 中只保存签名后的会话 ID，内容保存在 Backend
+type ServerStore struct {
+	Options *Options
+	backend Backend
+	codec   *Codec
+}
+
+func NewServerStore(backend Backend, hashKey []byte) (*ServerStore, error) {
+	codec, err := NewCodec(hashKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ServerStore{Options: DefaultOptions(), backend: backend, codec: codec}, nil
+}
+
+func (s *ServerStore) Get(r *http.Request, name string) (*Session, error) {
+	session := NewSession(name, s.Options)
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+
+	id, err := s.codec.Decode(name, cookie.Value, 0)
+	if err != nil {
+		return session, nil
+	}
+
+	data, err := s.backend.Load(r.Context(), string(id))
+	if errors.Is(err, ErrNotFound) {
+		return session, nil
+	}
+	if err != nil {
+		return session, err
+	}
+
+	values := make(map[string]any)
+	if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&values); err != nil {
+		return session, nil
+	}
+
+	session.ID, session.Values, session.IsNew = string(id), values, false
+	return session, nil
+}
+
+func (s *ServerStore) Save(w http.ResponseWriter, r *http.Request, session *Session) error {
+	ctx := r.Context()
+	if session.oldID != "" {
+		if err := s.backend.Delete(ctx, session.oldID); err != nil {
+			return err
+		}
+		session.oldID = ""
+	}
+
+	if session.Options.MaxAge < 0 {
+		if err := s.backend.Delete(ctx, session.ID); err != nil {
+			return err
+		}
+		http.SetCookie(w, session.cookie(""))
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(session.Values); err != nil {
+		return err
+	}
+	if err := s.backend.Save(ctx, session.ID, buf.Bytes(), session.expiry()); err != nil {
+		return err
+	}
+
+	value, err := s.codec.Encode(session.name, []byte(session.ID))
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, session.cookie(value))
+	return nil
+}
+
+// MemoryBackend 进程内会话存储，多实例部署时请实现共享的 Backend
+type MemoryBackend struct {
+	mu        sync.Mutex
+	items     map[string]memoryItem
+	lastClean time.Time
+}
+
+type memoryItem struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{items: make(map[string]memoryItem)}
+}
+
+func (b *MemoryBackend) Load(_ context.Context, id string) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	item, ok := b.items[id]
+	if !ok || (!item.expiresAt.IsZero() && time.Now().After(item.expiresAt)) {
+		return nil, ErrNotFound
+	}
+
+	return item.data, nil
+}
+
+func (b *MemoryBackend) Save(_ context.Context, id string, data []byte, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(b.lastClean) > time.Minute {
+		for key, item := range b.items {
+			if !item.expiresAt.IsZero() && now.After(item.expiresAt) {
+				delete(b.items, key)
+			}
+		}
+		b.lastClean = now
+	}
+
+	item := memoryItem{data: data}
+	if ttl > 0 {
+		item.expiresAt = now.Add(ttl)
+	}
+	b.items[id] = item
+	return nil
+}
+
+func (b *MemoryBackend) Delete(_ context.Context, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.items, id)
+	return nil
+}
@@ -0,0 +1,105 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// roundTrip 保存会话后把 Set-Cookie 带到下一个请求，返回读取到的会话
+func roundTrip(t *testing.T, store Store, session *Session) (*Session, *http.Cookie) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	if err := store.Save(rec, httptest.NewRequest("GET", "/", nil), session); err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Set-Cookie count = %d", len(cookies))
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookies[0])
+	next, err := store.Get(req, session.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return next, cookies[0]
+}
+
+func TestCookieStore(t *testing.T) {
+	store, err := NewCookieStore(testHashKey, testBlockKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, _ := store.Get(httptest.NewRequest("GET", "/", nil), "sid")
+	if !session.IsNew {
+		t.Fatal("session without cookie is not new")
+	}
+	session.Set("user", "u1")
+	session.AddFlash("saved")
+
+	next, cookie := roundTrip(t, store, session)
+	if next.IsNew || next.ID != session.ID || next.Get("user") != "u1" {
+		t.Fatalf("loaded session = %+v", next)
+	}
+	if flashes := next.Flashes(); len(flashes) != 1 || flashes[0] != "saved" || len(next.Flashes()) != 0 {
+		t.Fatal("flash was not read exactly once")
+	}
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("cookie attributes = %+v", cookie)
+	}
+
+	// 被篡改的 cookie 得到新会话
+	cookie.Value = "x" + cookie.Value
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookie)
+	if tampered, _ := store.Get(req, "sid"); !tampered.IsNew || tampered.Get("user") != nil {
+		t.Fatalf("tampered cookie loaded %+v", tampered)
+	}
+}
+
+func TestServerStore(t *testing.T) {
+	backend := NewMemoryBackend()
+	store, err := NewServerStore(backend, testHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := NewSession("sid", store.Options)
+	session.Set("user", "u1")
+	next, cookie := roundTrip(t, store, session)
+	if next.ID != session.ID || next.Get("user") != "u1" {
+		t.Fatalf("loaded session = %+v", next)
+	}
+	if cookie.Value == session.ID {
+		t.Fatal("session ID stored in the cookie unsigned")
+	}
+
+	// 登录后更换 ID，旧 ID 在保存时删除
+	oldID := next.ID
+	next.RotateID()
+	rotated, _ := roundTrip(t, store, next)
+	if rotated.ID == oldID || rotated.Get("user") != "u1" {
+		t.Fatalf("rotated session = %+v", rotated)
+	}
+	if _, err = backend.Load(context.Background(), oldID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("old session ID still loadable: %v", err)
+	}
+
+	rotated.Destroy()
+	rec := httptest.NewRecorder()
+	if err = store.Save(rec, httptest.NewRequest("GET", "/", nil), rotated); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = backend.Load(context.Background(), rotated.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("destroyed session still loadable: %v", err)
+	}
+	if cookies := rec.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Fatalf("destroy did not clear the cookie: %+v", cookies)
+	}
+}
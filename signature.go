@@ -0,0 +1,90 @@
+package water
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-water/water/auth"
+)
+
+// SecretFunc 按 X-Key-Id 返回共享密钥，单一密钥时可忽略 keyID
+type SecretFunc func(keyID string) ([]byte, error)
+
+type signatureAuth struct {
+	secret  SecretFunc
+	window  time.Duration
+	maxBody int64
+	nonces  auth.NonceStore
+}
+
+type SignatureOption func(s *signatureAuth)
+
+// SignatureWindow 允许的时间偏差，默认 5 分钟，超出视为过期
+func SignatureWindow(window time.Duration) SignatureOption {
+	return func(s *signatureAuth) { s.window = window }
+}
+
+// SignatureMaxBody 参与签名的 body 上限，默认 MaxMultipartMemory
+func SignatureMaxBody(n int64) SignatureOption {
+	return func(s *signatureAuth) { s.maxBody = n }
+}
+
+// SignatureNonces 使用 store 记录 X-Nonce，拒绝时间窗口内的重放请求，
+// 开启后不带 nonce 的请求返回 auth.ErrNonceMissing
+func SignatureNonces(store auth.NonceStore) SignatureOption {
+	return func(s *signatureAuth) { s.nonces = store }
+}
+
+// HMACAuth 校验 HMAC 请求签名，签名规则见 auth.CanonicalString，
+// 校验后 body 会被还原，后续仍可使用 ShouldBindJSON；
+// 默认只拒绝超出时间窗口的请求，窗口内的重放需要配合 SignatureNonces
+func HMACAuth(secret SecretFunc, options ...SignatureOption) Middleware {
+	s := &signatureAuth{
+		secret:  secret,
+		window:  5 * time.Minute,
+		maxBody: MaxMultipartMemory,
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			key, err := s.secret(c.GetHeader(auth.KeyIDHeader))
+			if err != nil {
+				_ = c.JSON(http.StatusUnauthorized, H{"err": auth.ErrSignatureInvalid.Error()})
+				return
+			}
+
+			if c.Request.Body != nil {
+				c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, s.maxBody)
+			}
+
+			if _, err = auth.VerifyRequest(c.Request, key, s.window); err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					_ = c.JSON(http.StatusRequestEntityTooLarge, H{"err": err.Error()})
+					return
+				}
+
+				_ = c.JSON(http.StatusUnauthorized, H{"err": err.Error()})
+				return
+			}
+
+			if s.nonces != nil {
+				if err = auth.VerifyNonce(c.Request, s.nonces, s.window); err != nil {
+					if !errors.Is(err, auth.ErrNonceMissing) && !errors.Is(err, auth.ErrSignatureReplayed) {
+						log.Error("nonce store failed", "err", err.Error())
+						err = ErrUnauthenticated
+					}
+
+					_ = c.JSON(http.StatusUnauthorized, H{"err": err.Error()})
+					return
+				}
+			}
+
+			next(c)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package water
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-water/water/auth"
+)
+
+func signatureSecret(keyID string) ([]byte, error) {
+	if keyID != "k1" {
+		return nil, auth.ErrSignatureInvalid
+	}
+	return []byte("secret"), nil
+}
+
+// signedRequest 返回签名后的请求，body 可以多次发送用于模拟重放
+func signedRequest(t *testing.T, body string) func() *http.Request {
+	t.Helper()
+	r := httptest.NewRequest("POST", "/orders", strings.NewReader(body))
+	if err := auth.SignRequest(r, "k1", []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+
+	return func() *http.Request {
+		replay := r.Clone(r.Context())
+		replay.Body = io.NopCloser(strings.NewReader(body))
+		return replay
+	}
+}
+
+func TestHMACAuth(t *testing.T) {
+	w := New()
+	var amount int
+	h := HMACAuth(signatureSecret)(func(c *Context) {
+		var req struct{ Amount int }
+		if err := c.ShouldBindJSON(&req); err != nil {
+			t.Errorf("ShouldBindJSON() after verification: %v", err)
+		}
+		amount = req.Amount
+	})
+
+	request := signedRequest(t, `{"amount":7}`)
+	if rec := serve(w, h, request()); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if amount != 7 {
+		t.Fatalf("amount = %d, want 7", amount)
+	}
+
+	// 没有 NonceStore 时窗口内的重放会通过
+	if rec := serve(w, h, request()); rec.Code != http.StatusOK {
+		t.Fatalf("replay status = %d without a nonce store", rec.Code)
+	}
+
+	r := request()
+	r.Header.Set(auth.KeyIDHeader, "unknown")
+	if rec := serve(w, h, r); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown key status = %d, want 401", rec.Code)
+	}
+}
+
+func TestHMACAuthRejectsReplay(t *testing.T) {
+	w := New()
+	h := HMACAuth(signatureSecret, SignatureNonces(auth.NewMemoryNonceStore()))(func(c *Context) {})
+
+	request := signedRequest(t, `{"amount":7}`)
+	if rec := serve(w, h, request()); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	rec := serve(w, h, request())
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), auth.ErrSignatureReplayed.Error()) {
+		t.Fatalf("replay = %d %s, want 401 replayed", rec.Code, rec.Body)
+	}
+}
+
+func TestHMACAuthRequiresNonce(t *testing.T) {
+	w := New()
+	h := HMACAuth(signatureSecret, SignatureNonces(auth.NewMemoryNonceStore()))(func(c *Context) {})
+
+	// 旧版客户端按 CanonicalString 签名，签名有效但没有 nonce
+	r := httptest.NewRequest("POST", "/orders", strings.NewReader(`{"amount":7}`))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	r.Header.Set(auth.KeyIDHeader, "k1")
+	r.Header.Set(auth.TimestampHeader, timestamp)
+	r.Header.Set(auth.SignatureHeader, auth.Sign([]byte("secret"), auth.CanonicalString("POST", "/orders", timestamp, []byte(`{"amount":7}`))))
+
+	rec := serve(w, h, r)
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), auth.ErrNonceMissing.Error()) {
+		t.Fatalf("missing nonce = %d %s, want 401 %q", rec.Code, rec.Body, auth.ErrNonceMissing)
+	}
+}
+
+func TestHMACAuthMaxBody(t *testing.T) {
+	w := New()
+	h := HMACAuth(signatureSecret, SignatureMaxBody(4))(func(c *Context) {})
+
+	if rec := serve(w, h, signedRequest(t, `{"amount":7}`)()); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413", rec.Code)
+	}
+}
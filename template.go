@@ -0,0 +1,32 @@
+package water
+
+import (
+	"html/template"
+)
+
+// TemplateFuncs 返回按请求绑定的内置模板函数的占位实现，解析模板时需要加入，例如
+// multitemplate.Render.AddFromFilesFuncs("index", water.TemplateFuncs(), files...)
+// 渲染时由 Context.HTML 替换为当前请求的实现：
+// {{ csrfField }} 输出 CSRF 隐藏字段，{{ csrfToken }} 输出 token，<script nonce="{{ cspNonce }}"> 使用 CSP nonce
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"csrfToken": func() string { return "" },
+		"csrfField": func() template.HTML { return "" },
+		"cspNonce":  func() string { return "" },
+	}
+}
+
+// templateFuncs 当前请求的模板函数，没有使用 CSRF 和 CSP nonce 时为空，渲染时不需要复制模板
+func (c *Context) templateFuncs() template.FuncMap {
+	_, csrf := c.Get(CSRFKey)
+	_, nonce := c.Get(CSPNonceKey)
+	if !csrf && !nonce {
+		return nil
+	}
+
+	return template.FuncMap{
+		"csrfToken": c.CSRFToken,
+		"csrfField": c.CSRFField,
+		"cspNonce":  c.CSPNonce,
+	}
+}
@@ -0,0 +1,114 @@
+package water
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-water/water/tracing"
+	"github.com/sony/gobreaker"
+)
+
+// traced 为请求创建服务端 Span，沿用 traceparent 中的上游追踪信息，Span 名称为 "方法 路由"
+func (r *RouterHandler) traced(c *Context) {
+	req := c.Request
+	route := c.Route()
+	name := req.Method
+	if route != "" {
+		name += " " + route
+	}
+
+	options := []tracing.StartOption{
+		tracing.WithSpanKind(tracing.SpanKindServer),
+		tracing.WithAttributes(
+			slog.String("http.request.method", req.Method),
+			slog.String("http.route", route),
+			slog.String("url.path", req.URL.Path),
+			slog.String("client.address", c.ClientIP()),
+		),
+	}
+	if sc, ok := tracing.Extract(req.Header); ok {
+		options = append(options, tracing.WithParent(sc))
+	}
+
+	ctx, span := r.wt.Tracer.Start(req.Context(), name, options...)
+	c.Request = req.WithContext(ctx)
+	c.Set(SpanKey, span)
+	defer func() {
+		status := c.ResponseStatus()
+		span.SetAttributes(slog.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(tracing.StatusError, http.StatusText(status))
+		}
+		span.End()
+	}()
+
+	r.h(c)
+}
+
+// spanFromContext 优先取 ctx 中的 Span，*Context 未开启 ContextWithFallback 时从 Keys 中取
+func spanFromContext(ctx context.Context) *tracing.Span {
+	if span := tracing.SpanFromContext(ctx); span != nil {
+		return span
+	}
+	if c, ok := FromContext(ctx); ok {
+		return c.Span()
+	}
+
+	return nil
+}
+
+// tracedSpan 服务调用的子 Span，记录调用前的熔断器状态用于比较
+type tracedSpan struct {
+	*tracing.Span
+	parent *tracing.Span
+	c      *Context
+	state  gobreaker.State
+}
+
+// startSpan 创建服务调用的子 Span，ctx 为 *Context 时原样返回，子 Span 暂存在 Keys 中，
+// 保证 Handle(ctx *water.Context, ...) 和类型断言在开启追踪后仍然可用
+func (h *handler) startSpan(ctx context.Context, parent *tracing.Span) (context.Context, *tracedSpan) {
+	next, span := parent.Tracer().Start(ctx, h.name,
+		tracing.WithParent(parent.SpanContext()),
+		tracing.WithAttributes(slog.String("water.service", h.name)),
+	)
+
+	s := &tracedSpan{Span: span, parent: parent}
+	if c, ok := ctx.(*Context); ok {
+		c.Set(SpanKey, span)
+		s.c = c
+		next = ctx
+	}
+	if h.breaker != nil {
+		s.state = h.breaker.State()
+	}
+	return next, s
+}
+
+// endSpan 记录调用结果，限流、熔断和错误以事件形式记录
+func (h *handler) endSpan(span *tracedSpan, err error, rejected bool) {
+	result := outcome(err, rejected)
+	span.SetAttributes(slog.String("water.outcome", result))
+
+	if h.breaker != nil {
+		state := h.breaker.State()
+		span.SetAttributes(slog.String("breaker.state", state.String()))
+		if state != span.state {
+			span.AddEvent("breaker_state_change",
+				slog.String("from", span.state.String()),
+				slog.String("to", state.String()),
+			)
+		}
+	}
+
+	switch result {
+	case OutcomeRateLimited, OutcomeBreakerOpen, OutcomeRejected:
+		span.AddEvent(result, slog.String("reason", err.Error()))
+	}
+	span.RecordError(err)
+	span.End()
+	if span.c != nil {
+		span.c.Set(SpanKey, span.parent)
+	}
+}
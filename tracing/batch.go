@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrQueueFull      = errors.New("tracing: export queue is full, span dropped")
+	ErrTracerShutdown = errors.New("tracing: tracer is shut down")
+)
+
+// export 把结束的 Span 放入队列，不阻塞调用方
+func (t *Tracer) export(data SpanData) {
+	if t.exporter == nil {
+		return
+	}
+
+	select {
+	case <-t.done:
+		t.error(ErrTracerShutdown)
+	case t.queue <- data:
+	default:
+		t.error(ErrQueueFull)
+	}
+}
+
+func (t *Tracer) run() {
+	defer close(t.stopped)
+
+	batch := make([]SpanData, 0, t.batchSize)
+	send := func() {
+		if len(batch) == 0 || t.exporter == nil {
+			return
+		}
+		t.error(t.exporter.Export(context.Background(), batch))
+		batch = make([]SpanData, 0, t.batchSize)
+	}
+	drain := func() {
+		for {
+			select {
+			case data := <-t.queue:
+				batch = append(batch, data)
+				if len(batch) >= t.batchSize {
+					send()
+				}
+			default:
+				send()
+				return
+			}
+		}
+	}
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case data := <-t.queue:
+			batch = append(batch, data)
+			if len(batch) >= t.batchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case ch := <-t.flush:
+			drain()
+			close(ch)
+		case <-t.done:
+			drain()
+			return
+		}
+	}
+}
+
+// Flush 导出队列中所有已结束的 Span，测试中读取 Recorder 前调用
+func (t *Tracer) Flush(ctx context.Context) error {
+	ch := make(chan struct{})
+	select {
+	case t.flush <- ch:
+	case <-t.done:
+		return ErrTracerShutdown
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown 导出剩余的 Span 并停止后台协程，之后结束的 Span 不再导出；
+// Exporter 实现了 Shutdown(ctx) error 时一并关闭
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	t.closeOnce.Do(func() { close(t.done) })
+
+	select {
+	case <-t.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	var err error
+	if s, ok := t.exporter.(interface{ Shutdown(context.Context) error }); ok {
+		t.exporterOnce.Do(func() { err = s.Shutdown(ctx) })
+	}
+
+	return err
+}
+
+func (t *Tracer) error(err error) {
+	if err != nil && t.onError != nil {
+		t.onError(err)
+	}
+}
@@ -0,0 +1,176 @@
+// Package otelexport 把 tracing 结束的 Span 转换为 OpenTelemetry SDK 的 ReadOnlySpan，
+// 交给任意 sdktrace.SpanExporter 导出，例如 otlptracehttp、otlptracegrpc 或 stdouttrace
+package otelexport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-water/water/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName 导出 Span 默认的 instrumentation scope
+const ScopeName = "github.com/go-water/water"
+
+type Option func(e *Exporter)
+
+// WithResource 设置导出 Span 的 resource，例如 service.name，默认为 resource.Default()
+func WithResource(res *resource.Resource) Option {
+	return func(e *Exporter) { e.resource = res }
+}
+
+// WithScope 设置导出 Span 的 instrumentation scope
+func WithScope(scope instrumentation.Scope) Option {
+	return func(e *Exporter) { e.scope = scope }
+}
+
+// Exporter 实现 tracing.Exporter，Tracer 关闭时同时关闭底层的 SpanExporter
+type Exporter struct {
+	exporter sdktrace.SpanExporter
+	resource *resource.Resource
+	scope    instrumentation.Scope
+}
+
+func New(exporter sdktrace.SpanExporter, options ...Option) *Exporter {
+	e := &Exporter{
+		exporter: exporter,
+		resource: resource.Default(),
+		scope:    instrumentation.Scope{Name: ScopeName},
+	}
+	for _, option := range options {
+		option(e)
+	}
+
+	return e
+}
+
+func (e *Exporter) Export(ctx context.Context, spans []tracing.SpanData) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	out := make([]sdktrace.ReadOnlySpan, 0, len(spans))
+	for _, data := range spans {
+		out = append(out, e.convert(data))
+	}
+
+	return e.exporter.ExportSpans(ctx, out)
+}
+
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	return e.exporter.Shutdown(ctx)
+}
+
+func (e *Exporter) convert(data tracing.SpanData) sdktrace.ReadOnlySpan {
+	stub := tracetest.SpanStub{
+		Name:                 data.Name,
+		SpanContext:          spanContext(data.SpanContext),
+		Parent:               spanContext(data.Parent),
+		SpanKind:             spanKind(data.Kind),
+		StartTime:            data.Start,
+		EndTime:              data.End,
+		Attributes:           attributes(data.Attrs),
+		Status:               status(data.Status, data.StatusDescription),
+		Resource:             e.resource,
+		InstrumentationScope: e.scope,
+	}
+	for _, event := range data.Events {
+		stub.Events = append(stub.Events, sdktrace.Event{
+			Name:       event.Name,
+			Time:       event.Time,
+			Attributes: attributes(event.Attrs),
+		})
+	}
+
+	return stub.Snapshot()
+}
+
+func spanContext(sc tracing.SpanContext) trace.SpanContext {
+	if !sc.IsValid() {
+		return trace.SpanContext{}
+	}
+
+	// tracestate 在入口处已经校验过格式，解析失败时丢弃
+	state, _ := trace.ParseTraceState(sc.TraceState)
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID(sc.TraceID),
+		SpanID:     trace.SpanID(sc.SpanID),
+		TraceFlags: trace.TraceFlags(sc.Flags),
+		TraceState: state,
+		Remote:     sc.Remote,
+	})
+}
+
+func spanKind(kind tracing.SpanKind) trace.SpanKind {
+	switch kind {
+	case tracing.SpanKindServer:
+		return trace.SpanKindServer
+	case tracing.SpanKindClient:
+		return trace.SpanKindClient
+	default:
+		return trace.SpanKindInternal
+	}
+}
+
+func status(code tracing.StatusCode, description string) sdktrace.Status {
+	switch code {
+	case tracing.StatusOK:
+		return sdktrace.Status{Code: codes.Ok}
+	case tracing.StatusError:
+		return sdktrace.Status{Code: codes.Error, Description: description}
+	default:
+		return sdktrace.Status{Code: codes.Unset}
+	}
+}
+
+// attributes 把 slog.Attr 转换为 OTel 属性，分组展开为 group.key
+func attributes(attrs []slog.Attr) []attribute.KeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	var walk func(prefix string, attrs []slog.Attr)
+	walk = func(prefix string, attrs []slog.Attr) {
+		for _, a := range attrs {
+			key := a.Key
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+
+			v := a.Value.Resolve()
+			switch v.Kind() {
+			case slog.KindGroup:
+				walk(key, v.Group())
+			case slog.KindString:
+				kvs = append(kvs, attribute.String(key, v.String()))
+			case slog.KindInt64:
+				kvs = append(kvs, attribute.Int64(key, v.Int64()))
+			case slog.KindUint64:
+				kvs = append(kvs, attribute.Int64(key, int64(v.Uint64())))
+			case slog.KindFloat64:
+				kvs = append(kvs, attribute.Float64(key, v.Float64()))
+			case slog.KindBool:
+				kvs = append(kvs, attribute.Bool(key, v.Bool()))
+			case slog.KindDuration:
+				kvs = append(kvs, attribute.Int64(key, v.Duration().Nanoseconds()))
+			case slog.KindTime:
+				kvs = append(kvs, attribute.String(key, v.Time().Format(time.RFC3339Nano)))
+			default:
+				kvs = append(kvs, attribute.String(key, fmt.Sprint(v.Any())))
+			}
+		}
+	}
+	walk("", attrs)
+
+	return kvs
+}
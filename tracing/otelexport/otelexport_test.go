@@ -0,0 +1,104 @@
+package otelexport
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-water/water/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type countingExporter struct {
+	*tracetest.InMemoryExporter
+	shutdowns int
+}
+
+func (e *countingExporter) Shutdown(ctx context.Context) error {
+	e.shutdowns++
+	return e.InMemoryExporter.Shutdown(ctx)
+}
+
+func TestExporter(t *testing.T) {
+	mem := &countingExporter{InMemoryExporter: tracetest.NewInMemoryExporter()}
+	res := resource.NewSchemaless(attribute.String("service.name", "api"))
+	tracer := tracing.NewTracer(New(mem, WithResource(res)))
+
+	remote, _ := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	remote.TraceState = "vendor=value"
+	ctx, root := tracer.Start(context.Background(), "GET /users/{id}",
+		tracing.WithSpanKind(tracing.SpanKindServer),
+		tracing.WithParent(remote),
+		tracing.WithAttributes(slog.String("http.route", "/users/{id}"), slog.Int("http.response.status_code", 500)),
+	)
+	_, child := tracer.Start(ctx, "service", tracing.WithAttributes(slog.Group("db", slog.Bool("cached", true)), slog.Duration("wait", time.Millisecond)))
+	child.RecordError(errors.New("boom"))
+	child.End()
+	root.SetStatus(tracing.StatusOK, "")
+	root.End()
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := tracer.Flush(flushCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := mem.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	c, r := spans[0], spans[1]
+
+	if r.SpanKind != trace.SpanKindServer || r.Status.Code != codes.Ok {
+		t.Errorf("root kind = %v, status = %v", r.SpanKind, r.Status)
+	}
+	if r.Parent.SpanID().String() != remote.SpanID.String() || !r.Parent.IsRemote() {
+		t.Errorf("root parent = %v, want remote %s", r.Parent, remote.SpanID)
+	}
+	if r.SpanContext.TraceID().String() != remote.TraceID.String() || r.SpanContext.TraceState().Get("vendor") != "value" {
+		t.Errorf("root span context = %v", r.SpanContext)
+	}
+	if got := attrs(r.Attributes); got["http.route"] != attribute.StringValue("/users/{id}") || got["http.response.status_code"] != attribute.Int64Value(500) {
+		t.Errorf("root attributes = %v", r.Attributes)
+	}
+	if r.Resource != res || r.InstrumentationScope.Name != ScopeName {
+		t.Errorf("root resource = %v, scope = %v", r.Resource, r.InstrumentationScope)
+	}
+
+	if c.Parent.SpanID() != r.SpanContext.SpanID() || c.SpanKind != trace.SpanKindInternal {
+		t.Errorf("child parent = %v, kind = %v", c.Parent, c.SpanKind)
+	}
+	if c.Status.Code != codes.Error || c.Status.Description != "boom" {
+		t.Errorf("child status = %v", c.Status)
+	}
+	if got := attrs(c.Attributes); got["db.cached"] != attribute.BoolValue(true) || got["wait"] != attribute.Int64Value(int64(time.Millisecond)) {
+		t.Errorf("child attributes = %v", c.Attributes)
+	}
+	if len(c.Events) != 1 || c.Events[0].Name != "exception" || attrs(c.Events[0].Attributes)["exception.message"] != attribute.StringValue("boom") {
+		t.Errorf("child events = %+v", c.Events)
+	}
+
+	// Tracer 关闭时一并关闭底层 exporter，且只关闭一次
+	for i := 0; i < 2; i++ {
+		if err := tracer.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if mem.shutdowns != 1 {
+		t.Fatalf("exporter shut down %d times, want 1", mem.shutdowns)
+	}
+}
+
+func attrs(kvs []attribute.KeyValue) map[string]attribute.Value {
+	m := make(map[string]attribute.Value, len(kvs))
+	for _, kv := range kvs {
+		m[string(kv.Key)] = kv.Value
+	}
+	return m
+}
@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Recorder 把结束的 Span 保存在内存中，用于测试，读取前先调用 Tracer.Flush
+type Recorder struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func NewRecorder() *Recorder {
+	return new(Recorder)
+}
+
+func (r *Recorder) Export(_ context.Context, spans []SpanData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+// Spans 按结束顺序返回已记录的 Span
+func (r *Recorder) Spans() []SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.spans)
+}
+
+// Find 返回指定名称的 Span
+func (r *Recorder) Find(name string) []SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var spans []SpanData
+	for _, span := range r.spans {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = nil
+}
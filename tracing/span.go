@@ -0,0 +1,172 @@
+package tracing
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+	SpanKindClient
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = iota
+	StatusOK
+	StatusError
+)
+
+type Event struct {
+	Name  string
+	Time  time.Time
+	Attrs []slog.Attr
+}
+
+// SpanData 结束后的 Span 快照，交给 Exporter 导出
+type SpanData struct {
+	Name              string
+	Kind              SpanKind
+	SpanContext       SpanContext
+	Parent            SpanContext
+	Start             time.Time
+	End               time.Time
+	Attrs             []slog.Attr
+	Events            []Event
+	Status            StatusCode
+	StatusDescription string
+}
+
+func (d SpanData) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
+
+// Span 一次操作的耗时记录，未采样时所有方法都不做记录
+type Span struct {
+	tracer *Tracer
+	sc     SpanContext
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+
+	return s.sc
+}
+
+// Tracer 返回创建该 Span 的 Tracer，用于创建子 Span
+func (s *Span) Tracer() *Tracer {
+	if s == nil {
+		return nil
+	}
+
+	return s.tracer
+}
+
+func (s *Span) IsRecording() bool {
+	if s == nil || !s.sc.Sampled() {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.ended
+}
+
+func (s *Span) SetName(name string) {
+	s.update(func(d *SpanData) { d.Name = name })
+}
+
+func (s *Span) SetAttributes(attrs ...slog.Attr) {
+	s.update(func(d *SpanData) { d.Attrs = append(d.Attrs, attrs...) })
+}
+
+func (s *Span) AddEvent(name string, attrs ...slog.Attr) {
+	now := time.Now()
+	s.update(func(d *SpanData) {
+		d.Events = append(d.Events, Event{Name: name, Time: now, Attrs: attrs})
+	})
+}
+
+// RecordError 以 exception 事件记录错误，并把状态设置为 StatusError
+func (s *Span) RecordError(err error, attrs ...slog.Attr) {
+	if err == nil {
+		return
+	}
+
+	s.AddEvent("exception", append([]slog.Attr{slog.String("exception.message", err.Error())}, attrs...)...)
+	s.SetStatus(StatusError, err.Error())
+}
+
+func (s *Span) SetStatus(code StatusCode, description string) {
+	s.update(func(d *SpanData) {
+		// StatusOK 为最终状态，不再被覆盖
+		if d.Status == StatusOK {
+			return
+		}
+		d.Status = code
+		if code == StatusError {
+			d.StatusDescription = description
+		}
+	})
+}
+
+// End 结束 Span 并导出，重复调用只生效一次
+func (s *Span) End() {
+	if s == nil || !s.sc.Sampled() {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.snapshot()
+	s.mu.Unlock()
+
+	s.tracer.export(data)
+}
+
+func (s *Span) update(fn func(d *SpanData)) {
+	if s == nil || !s.sc.Sampled() {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		fn(&s.data)
+	}
+}
+
+func (s *Span) snapshot() SpanData {
+	data := s.data
+	data.Attrs = slices.Clone(data.Attrs)
+	data.Events = slices.Clone(data.Events)
+	return data
+}
@@ -0,0 +1,194 @@
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Exporter 导出结束的 Span，由 Tracer 的后台协程分批调用，不在请求路径上执行
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+type ExporterFunc func(ctx context.Context, spans []SpanData) error
+
+func (f ExporterFunc) Export(ctx context.Context, spans []SpanData) error {
+	return f(ctx, spans)
+}
+
+type Tracer struct {
+	exporter Exporter
+	ratio    float64
+	onError  func(err error)
+
+	batchSize int
+	interval  time.Duration
+	queue     chan SpanData
+	flush     chan chan struct{}
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+
+	exporterOnce sync.Once
+}
+
+type TracerOption func(t *Tracer)
+
+// TracerSampleRatio 根 Span 的采样率，取值 0~1，默认全部采样；有上游时沿用上游的采样标记
+func TracerSampleRatio(ratio float64) TracerOption {
+	return func(t *Tracer) { t.ratio = ratio }
+}
+
+// TracerBatch 每批导出的最大数量和最长等待时间，默认 512 个、5 秒
+func TracerBatch(size int, interval time.Duration) TracerOption {
+	return func(t *Tracer) {
+		t.batchSize = size
+		t.interval = interval
+	}
+}
+
+// TracerQueueSize 等待导出的队列长度，默认 2048，队列满时丢弃新结束的 Span 并报告 ErrQueueFull
+func TracerQueueSize(size int) TracerOption {
+	return func(t *Tracer) { t.queue = make(chan SpanData, size) }
+}
+
+// TracerErrorHandler 处理导出失败，默认忽略
+func TracerErrorHandler(fn func(err error)) TracerOption {
+	return func(t *Tracer) { t.onError = fn }
+}
+
+// NewTracer 创建 Tracer 并启动后台导出协程，退出前调用 Shutdown 导出剩余的 Span
+func NewTracer(exporter Exporter, options ...TracerOption) *Tracer {
+	t := &Tracer{
+		exporter:  exporter,
+		ratio:     1,
+		batchSize: 512,
+		interval:  5 * time.Second,
+		flush:     make(chan chan struct{}),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	for _, option := range options {
+		option(t)
+	}
+	if t.queue == nil {
+		t.queue = make(chan SpanData, 2048)
+	}
+	if t.batchSize <= 0 {
+		t.batchSize = 512
+	}
+	if t.interval <= 0 {
+		t.interval = 5 * time.Second
+	}
+
+	go t.run()
+	return t
+}
+
+type startConfig struct {
+	kind   SpanKind
+	attrs  []slog.Attr
+	parent *SpanContext
+}
+
+type StartOption func(c *startConfig)
+
+func WithSpanKind(kind SpanKind) StartOption {
+	return func(c *startConfig) { c.kind = kind }
+}
+
+func WithAttributes(attrs ...slog.Attr) StartOption {
+	return func(c *startConfig) { c.attrs = append(c.attrs, attrs...) }
+}
+
+// WithParent 指定父级，常用于上游传来的 SpanContext，优先于 ctx 中的 Span
+func WithParent(sc SpanContext) StartOption {
+	return func(c *startConfig) { c.parent = &sc }
+}
+
+// Start 创建 Span，父级为 ctx 中的 Span，返回的 ctx 携带新的 Span
+func (t *Tracer) Start(ctx context.Context, name string, options ...StartOption) (context.Context, *Span) {
+	cfg := new(startConfig)
+	for _, option := range options {
+		option(cfg)
+	}
+
+	var parent SpanContext
+	if cfg.parent != nil {
+		parent = *cfg.parent
+	} else if span := SpanFromContext(ctx); span != nil {
+		parent = span.SpanContext()
+	}
+
+	sc := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.TraceState = parent.TraceState
+		sc.Flags = parent.Flags
+	} else {
+		sc.TraceID = newTraceID()
+		if t.ratio >= 1 || rand.Float64() < t.ratio {
+			sc.Flags = flagSampled
+		}
+	}
+
+	span := &Span{
+		tracer: t,
+		sc:     sc,
+		data: SpanData{
+			Name:        name,
+			Kind:        cfg.kind,
+			SpanContext: sc,
+			Parent:      parent,
+			Start:       time.Now(),
+			Attrs:       cfg.attrs,
+		},
+	}
+
+	return ContextWithSpan(ctx, span), span
+}
+
+type spanKey struct{}
+
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanCarrier 自身保存当前 Span 的 context，如 *water.Context
+type SpanCarrier interface {
+	Span() *Span
+}
+
+// SpanFromContext 返回 ctx 中的 Span，没有时返回 nil，nil Span 的方法均可安全调用
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	if carrier, ok := ctx.(SpanCarrier); ok {
+		if span := carrier.Span(); span != nil {
+			return span
+		}
+	}
+
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+func newTraceID() (id TraceID) {
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	}
+	return
+}
+
+func newSpanID() (id SpanID) {
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return
+}
@@ -0,0 +1,172 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func flush(t *testing.T, tracer *Tracer) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := tracer.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		in string
+		ok bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		sc, ok := ParseTraceparent(tt.in)
+		if ok != tt.ok {
+			t.Errorf("ParseTraceparent(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+		}
+		if ok && tt.in[:2] == "00" && sc.Traceparent() != tt.in {
+			t.Errorf("Traceparent() = %q, want %q", sc.Traceparent(), tt.in)
+		}
+	}
+}
+
+func TestExtractInject(t *testing.T) {
+	header := http.Header{}
+	header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	header.Set(TracestateHeader, "vendor=value")
+
+	sc, ok := Extract(header)
+	if !ok || !sc.Remote || !sc.Sampled() || sc.TraceState != "vendor=value" {
+		t.Fatalf("Extract() = %+v, %v", sc, ok)
+	}
+
+	out := http.Header{}
+	Inject(sc, out)
+	if out.Get(TraceparentHeader) != header.Get(TraceparentHeader) || out.Get(TracestateHeader) != "vendor=value" {
+		t.Fatalf("Inject() = %v", out)
+	}
+}
+
+func TestRecorderParentChild(t *testing.T) {
+	rec := NewRecorder()
+	tracer := NewTracer(rec)
+	defer tracer.Shutdown(context.Background())
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, root := tracer.Start(context.Background(), "root", WithSpanKind(SpanKindServer), WithParent(remote))
+	_, child := tracer.Start(ctx, "child")
+	child.AddEvent("rate_limited")
+	child.RecordError(errors.New("boom"))
+	child.End()
+	root.End()
+	root.End()
+
+	flush(t, tracer)
+	spans := rec.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(spans))
+	}
+
+	c, r := rec.Find("child")[0], rec.Find("root")[0]
+	if r.Parent.SpanID != remote.SpanID || r.SpanContext.TraceID != remote.TraceID {
+		t.Errorf("root parent = %+v, want remote %+v", r.Parent, remote)
+	}
+	if c.Parent.SpanID != r.SpanContext.SpanID || c.SpanContext.TraceID != r.SpanContext.TraceID {
+		t.Errorf("child parent = %+v, want root %+v", c.Parent, r.SpanContext)
+	}
+	if c.Status != StatusError || c.StatusDescription != "boom" {
+		t.Errorf("child status = %v %q", c.Status, c.StatusDescription)
+	}
+	if len(c.Events) != 2 || c.Events[0].Name != "rate_limited" || c.Events[1].Name != "exception" {
+		t.Errorf("child events = %+v", c.Events)
+	}
+}
+
+func TestUnsampledSpansAreNotExported(t *testing.T) {
+	rec := NewRecorder()
+	tracer := NewTracer(rec, TracerSampleRatio(0))
+	defer tracer.Shutdown(context.Background())
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	_, child := tracer.Start(ctx, "child")
+	if root.IsRecording() || child.IsRecording() {
+		t.Fatal("unsampled span is recording")
+	}
+	if child.SpanContext().TraceID != root.SpanContext().TraceID {
+		t.Fatal("unsampled child does not share the trace id")
+	}
+	child.End()
+	root.End()
+
+	flush(t, tracer)
+	if spans := rec.Spans(); len(spans) != 0 {
+		t.Fatalf("recorded %d unsampled spans", len(spans))
+	}
+}
+
+func TestExportDoesNotBlockEnd(t *testing.T) {
+	release := make(chan struct{})
+	exporter := ExporterFunc(func(ctx context.Context, spans []SpanData) error {
+		<-release
+		return nil
+	})
+
+	var dropped int
+	tracer := NewTracer(exporter, TracerQueueSize(1), TracerBatch(1, time.Hour), TracerErrorHandler(func(err error) {
+		if errors.Is(err, ErrQueueFull) {
+			dropped++
+		}
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			_, span := tracer.Start(context.Background(), "span")
+			span.End()
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("End blocked on a slow exporter")
+	}
+	if dropped == 0 {
+		t.Fatal("expected spans to be dropped while the queue is full")
+	}
+
+	close(release)
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShutdownExportsPendingSpans(t *testing.T) {
+	rec := NewRecorder()
+	tracer := NewTracer(rec, TracerBatch(100, time.Hour))
+	for i := 0; i < 3; i++ {
+		_, span := tracer.Start(context.Background(), "span")
+		span.End()
+	}
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rec.Spans()); n != 3 {
+		t.Fatalf("recorded %d spans after shutdown, want 3", n)
+	}
+}
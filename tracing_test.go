@@ -0,0 +1,110 @@
+package water
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-water/water/tracing"
+)
+
+type tracedRequest struct{}
+
+type contextService struct{ ServerBase }
+
+func (s *contextService) Handle(ctx *Context, req *tracedRequest) (string, error) {
+	_, span := tracing.SpanFromContext(ctx).Tracer().Start(ctx, "inner")
+	span.End()
+	return ctx.Route(), nil
+}
+
+type limitedService struct{ ServerBase }
+
+func (s *limitedService) Handle(ctx context.Context, req *tracedRequest) (string, error) {
+	return "", errors.New("boom")
+}
+
+func serveTraced(t *testing.T, w *Water, pattern, target string, h HandlerFunc) {
+	t.Helper()
+	req := httptest.NewRequest("GET", target, nil)
+	req.Pattern = pattern
+	req.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	(&RouterHandler{wt: w, h: h}).ServeHTTP(httptest.NewRecorder(), req)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := w.Tracer.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func attr(span tracing.SpanData, key string) string {
+	for _, a := range span.Attrs {
+		if a.Key == key {
+			return a.Value.String()
+		}
+	}
+	return ""
+}
+
+func TestTracingKeepsWaterContext(t *testing.T) {
+	rec := tracing.NewRecorder()
+	w := New()
+	w.Tracer = tracing.NewTracer(rec)
+	defer w.Tracer.Shutdown(context.Background())
+
+	h := NewHandler(&contextService{})
+	var resp any
+	var err error
+	serveTraced(t, w, "GET /users/{id}", "/users/1", func(c *Context) {
+		resp, err = h.ServerWater(c, &tracedRequest{})
+	})
+	if err != nil || resp != "/users/{id}" {
+		t.Fatalf("ServerWater() = %v, %v", resp, err)
+	}
+
+	server, service, inner := rec.Find("GET /users/{id}"), rec.Find("contextService"), rec.Find("inner")
+	if len(server) != 1 || len(service) != 1 || len(inner) != 1 {
+		t.Fatalf("spans = %+v", rec.Spans())
+	}
+	if got := attr(server[0], "http.route"); got != "/users/{id}" {
+		t.Errorf("http.route = %q", got)
+	}
+	if server[0].Parent.SpanID.String() != "00f067aa0ba902b7" {
+		t.Errorf("server span parent = %s", server[0].Parent.SpanID)
+	}
+	if service[0].Parent.SpanID != server[0].SpanContext.SpanID {
+		t.Error("service span is not a child of the server span")
+	}
+	if inner[0].Parent.SpanID != service[0].SpanContext.SpanID {
+		t.Error("inner span is not a child of the service span")
+	}
+}
+
+func TestTracingRecordsRateLimit(t *testing.T) {
+	rec := tracing.NewRecorder()
+	w := New()
+	w.Tracer = tracing.NewTracer(rec)
+	defer w.Tracer.Shutdown(context.Background())
+
+	h := NewHandler(&limitedService{}, ServerErrorLimiter(time.Hour, 1))
+	fn := func(c *Context) { _, _ = h.ServerWater(c, &tracedRequest{}) }
+	serveTraced(t, w, "GET /", "/", fn)
+	serveTraced(t, w, "GET /", "/", fn)
+
+	spans := rec.Find("limitedService")
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d service spans, want 2", len(spans))
+	}
+	if got := attr(spans[0], "water.outcome"); got != OutcomeError {
+		t.Errorf("first outcome = %q", got)
+	}
+	if got := attr(spans[1], "water.outcome"); got != OutcomeRateLimited {
+		t.Errorf("second outcome = %q", got)
+	}
+	if spans[1].Events[0].Name != OutcomeRateLimited {
+		t.Errorf("second span events = %+v", spans[1].Events)
+	}
+}
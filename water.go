@@ -1,16 +1,21 @@
 package water
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"net"
 	"net/http"
+	"net/netip"
 	"path"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/go-water/water/health"
 	"github.com/go-water/water/render"
+	"github.com/go-water/water/tracing"
 )
 
 const defaultMultipartMemory = 32 << 20 // 32 MB
@@ -22,6 +27,17 @@ type Water struct {
 	pool                sync.Pool
 	TrustedPlatform     string
 	RemoteIPHeaders     []string
+	// Tracer 非空时为每个请求创建 server Span，
+	// 导出到 OpenTelemetry collector 时使用 otelexport.New 包装 OTLP exporter
+	Tracer         *tracing.Tracer
+	trustedProxies []netip.Prefix
+	server         atomic.Pointer[http.Server]
+	health         *health.Registry
+
+	// Debug 为 true 时 Run 打印路由表代替启动图案
+	Debug bool
+	// StrictRoutes 为 true 时重复注册路由直接 panic，适合在 CI 中开启
+	StrictRoutes bool
 
 	MaxMultipartMemory int64
 }
@@ -29,17 +45,15 @@ type Water struct {
 func New() *Water {
 	w := &Water{
 		Router: Router{
-			routes: make(map[string]HandlerFunc),
 			base: &base{
 				global: make([]HttpHandler, 0),
-				routes: make(map[string]*Router),
 			},
 		},
-		RemoteIPHeaders:    []string{"X-Forwarded-For", "X-Real-IP"},
+		RemoteIPHeaders:    []string{"X-Forwarded-For", "X-Real-IP", "Forwarded"},
 		MaxMultipartMemory: defaultMultipartMemory,
 	}
 
-	w.base.routes[""] = &w.Router
+	w.base.wt = w
 	w.pool.New = func() any {
 		return w.allocateContext()
 	}
@@ -50,12 +64,10 @@ func New() *Water {
 func (w *Water) Run(addr string, server ...*http.Server) error {
 	mux := &http.ServeMux{}
 	for _, rt := range w.base.routes {
-		for url, handle := range rt.routes {
-			rhd := new(RouterHandler)
-			rhd.wt = w
-			rhd.h = handle
-			mux.Handle(url, rhd)
-		}
+		rhd := new(RouterHandler)
+		rhd.wt = w
+		rhd.h = rt.handler
+		mux.Handle(rt.Method+" "+rt.Pattern, rhd)
 	}
 
 	var h http.Handler = mux
@@ -70,29 +82,67 @@ func (w *Water) Run(addr string, server ...*http.Server) error {
 		srv = server[0]
 	}
 	srv.Addr, srv.Handler = addr, h
-	w.print(addr)
+	w.server.Store(srv)
+	if w.Debug {
+		w.printRoutes(addr)
+	} else {
+		w.print(addr)
+	}
 	return srv.ListenAndServe()
 }
 
+// Shutdown 优雅关闭，配置了 Health 时先让就绪检查失败并等待 DrainDelay，
+// 再停止接收新请求并等待处理中的请求完成，最后导出剩余的追踪数据并停止公钥文件的监听
+func (w *Water) Shutdown(ctx context.Context) error {
+	if w.health != nil {
+		w.health.Shutdown()
+		if d := w.health.DrainDelay(); d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+			}
+		}
+	}
+
+	var err error
+	if srv := w.server.Load(); srv != nil {
+		err = srv.Shutdown(ctx)
+	}
+	if w.Tracer != nil {
+		err = errors.Join(err, w.Tracer.Shutdown(ctx))
+	}
+	CloseKeyFiles()
+	return err
+}
+
+// UseHttpHandler 注册全局 http 中间件，在路由匹配之前执行，先注册的先执行
+func (w *Water) UseHttpHandler(handlers ...HttpHandler) {
+	handlers = slices.Clone(handlers)
+	slices.Reverse(handlers)
+	w.base.global = slices.Concat(handlers, w.base.global)
+}
+
 func (w *Water) allocateContext() *Context {
 	return &Context{wt: w}
 }
 
-func (w *Water) validateHeader(header string) (clientIP string, valid bool) {
+// validateHeader 从右向左跳过可信代理，返回第一个不可信的地址
+func (w *Water) validateHeader(headerName, header string) (clientIP string, valid bool) {
 	if header == "" {
 		return "", false
 	}
 
-	items := strings.Split(header, ",")
+	items := forwardedFor(headerName, header)
 	for i := len(items) - 1; i >= 0; i-- {
-		ipStr := strings.TrimSpace(items[i])
-		ip := net.ParseIP(ipStr)
-		if ip == nil {
+		addr, err := netip.ParseAddr(strings.TrimSpace(items[i]))
+		if err != nil {
 			break
 		}
 
-		if i == 0 {
-			return ipStr, true
+		if i == 0 || !w.isTrustedProxy(addr) {
+			return addr.Unmap().String(), true
 		}
 	}
 
@@ -110,7 +160,6 @@ func (w *Water) print(addr string) {
 
 type Router struct {
 	scope       string
-	routes      map[string]HandlerFunc
 	middlewares []Middleware
 	base        *base
 
@@ -119,20 +168,18 @@ type Router struct {
 
 type base struct {
 	global []HttpHandler
-	routes map[string]*Router
+	routes []*registration
+	mux    *http.ServeMux
+	wt     *Water
 }
 
 func (r *Router) Group(prefix string) *Router {
 	newScope := r.scope + prefix
-	newRouter := &Router{
+	return &Router{
 		scope:       newScope,
-		routes:      make(map[string]HandlerFunc),
 		middlewares: r.middlewares,
 		base:        r.base,
 	}
-
-	r.base.routes[newScope] = newRouter
-	return newRouter
 }
 
 func (r *Router) POST(route string, handler HandlerFunc) {
@@ -168,8 +215,17 @@ func (r *Router) Method(method, route string, handler HandlerFunc) {
 		route += "{$}"
 	}
 
-	router := r.base.routes[r.scope]
-	router.routes[method+" "+r.scope+route] = r.withMiddlewares(handler)
+	r.base.add(&registration{
+		RouteInfo: RouteInfo{
+			Method:      method,
+			Pattern:     r.scope + route,
+			Scope:       r.scope,
+			Middlewares: len(r.middlewares),
+			Handler:     nameOfFunction(handler),
+			Source:      callerSource(),
+		},
+		handler: r.withMiddlewares(handler),
+	})
 }
 
 func (r *Router) StaticFile(relativePath, filepath string) *Router {